@@ -6,17 +6,24 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	apiHttp "github.com/newnorthblog/backend/internal/api/http"
 	"github.com/newnorthblog/backend/internal/config"
 	"github.com/newnorthblog/backend/internal/db"
+	"github.com/newnorthblog/backend/internal/pkg/mailer"
+	"github.com/newnorthblog/backend/internal/pkg/storage"
 	"github.com/newnorthblog/backend/internal/pkg/tokenmanager"
 	"github.com/newnorthblog/backend/internal/repository"
 	"github.com/newnorthblog/backend/internal/server"
 	"github.com/newnorthblog/backend/internal/service"
+	"github.com/newnorthblog/backend/pkg/limiter"
 	"github.com/newnorthblog/backend/pkg/logger"
+
+	"github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
 )
 
 func main() {
@@ -44,6 +51,49 @@ func main() {
 	}()
 	logger.Info("postgres connection done")
 
+	listener, err := db.NewListener(cfg.Database, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			logger.Error("postgres listener problem", "event", event, "error", err)
+		}
+	})
+	if err != nil {
+		logger.Error("postgres listener error", "error", err)
+		os.Exit(1)
+	}
+
+	// Init rate limiter store
+	var limiterStore limiter.Store
+	switch cfg.Limiter.Store {
+	case "memory":
+		limiterStore = limiter.NewMemoryStore(cfg.Limiter.RPS, cfg.Limiter.Burst, cfg.Limiter.TTL)
+	case "redis":
+		redisOptions, err := redis.ParseURL(cfg.Limiter.RedisURL)
+		if err != nil {
+			logger.Error("redis url problem", "error", err)
+			os.Exit(1)
+		}
+		redisClient := redis.NewClient(redisOptions)
+		defer func() {
+			err = redisClient.Close()
+			if err != nil {
+				logger.Error("error when closing", "error", err)
+			}
+		}()
+		if err := redisClient.Ping(context.Background()).Err(); err != nil {
+			logger.Error("redis connection problem", "error", err)
+			os.Exit(1)
+		}
+		logger.Info("redis connection done")
+		limiterStore, err = limiter.NewRedisStore(redisClient, cfg.Limiter.RPS, cfg.Limiter.Burst)
+		if err != nil {
+			logger.Error("rate limiter store error", "error", err)
+			os.Exit(1)
+		}
+	default:
+		logger.Error("unknown rate limiter store", "store", cfg.Limiter.Store)
+		os.Exit(1)
+	}
+
 	// Init services, repositories, handlers
 	repos := repository.NewRepositories(dbPostgres)
 	tokenManager, err := tokenmanager.NewManager(cfg.JWT.SecretKey, cfg.JWT.AccessTokenTTL, cfg.JWT.RefreshTokenTTL)
@@ -51,20 +101,53 @@ func main() {
 		logger.Error("token manager error", "error", err)
 		os.Exit(1)
 	}
+	storageBackend, err := storage.New(cfg.Storage)
+	if err != nil {
+		logger.Error("storage backend error", "error", err)
+		os.Exit(1)
+	}
+	mailSender, err := mailer.New(cfg.Mailer)
+	if err != nil {
+		logger.Error("mailer error", "error", err)
+		os.Exit(1)
+	}
 	services := service.NewServices(service.Deps{
 		Logger:       logger,
 		Config:       cfg,
 		Repos:        repos,
 		TokenManager: tokenManager,
+		Storage:      storageBackend,
+		Listener:     listener,
+		Mailer:       mailSender,
 	})
+	// Promote the first admin
+	if cfg.Admin.Email != "" {
+		if err := services.UserAdmin.PromoteAdmin(context.Background(), cfg.Admin.Email); err != nil {
+			logger.Error("admin promotion problem", "error", err)
+		}
+	}
+
 	handlers := apiHttp.NewHandlers(
 		services,
 		logger,
 		tokenManager,
+		limiterStore,
 	)
 
+	// Start background workers
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	for _, worker := range services.Workers {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			worker.Run(workersCtx)
+		}()
+	}
+
 	// Init HTTP server
 	srv := server.NewServer(cfg, handlers.Init(cfg))
+	srv.RegisterOnShutdown(services.Stream.Shutdown)
 	go func() {
 		if err := srv.Run(); !errors.Is(err, http.ErrServerClosed) {
 			logger.Error("error occurred while running http server", "error", err)
@@ -87,5 +170,8 @@ func main() {
 		logger.Error("failed to stop server", "error", err)
 	}
 
+	stopWorkers()
+	workers.Wait()
+
 	logger.Info("app stopped")
 }
@@ -0,0 +1,551 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/newnorthblog/backend/internal/domain"
+	"github.com/newnorthblog/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) initAdminRoutes(api *gin.RouterGroup) {
+	admin := api.Group("/admin", h.userIdentityMiddleware, h.adminMiddleware)
+
+	reports := admin.Group("/reports")
+	reports.GET("", h.adminReportList)
+	reports.POST("/:id/resolve", h.adminReportResolve)
+
+	users := admin.Group("/users")
+	users.GET("", h.adminUserList)
+	users.POST("/:id/suspend", h.adminUserSuspend)
+	users.POST("/:id/ban", h.adminUserBan)
+	users.POST("/:id/reinstate", h.adminUserReinstate)
+	users.POST("/:id/role", h.adminUserChangeRole)
+	users.POST("/:id/password-reset", h.adminUserPasswordReset)
+	users.POST("/:id/verify-email", h.adminUserVerifyEmail)
+	users.POST("/:id/impersonate", h.adminUserImpersonate)
+
+	admin.GET("/audit", h.adminAuditList)
+
+	issues := admin.Group("/newsletter/issues")
+	issues.GET("", h.adminNewsletterIssueList)
+	issues.POST("", h.adminNewsletterIssueCreate)
+	issues.POST("/:id/send", h.adminNewsletterIssueSend)
+
+	webhooks := admin.Group("/webhooks")
+	webhooks.GET("", h.adminWebhookList)
+	webhooks.POST("", h.adminWebhookCreate)
+	webhooks.PUT("/:id", h.adminWebhookUpdate)
+	webhooks.DELETE("/:id", h.adminWebhookDelete)
+	webhooks.GET("/:id/deliveries", h.adminWebhookDeliveryList)
+
+	deliveries := admin.Group("/webhook-deliveries")
+	deliveries.GET("/:id", h.adminWebhookDeliveryGet)
+	deliveries.POST("/:id/redeliver", h.adminWebhookRedeliver)
+}
+
+type adminReportListRequest struct {
+	cursorRequest
+	Status string `form:"status" binding:"omitempty,oneof=open resolved dismissed"`
+}
+
+type reportListResponse struct {
+	Items      []reportResponse `json:"items"`
+	NextCursor string           `json:"next_cursor"`
+}
+
+// @Summary Жалобы
+// @Tags Admin
+// @Description Список жалоб для модерации
+// @ModuleID Admin
+// @Accept  json
+// @Produce  json
+// @Param status query string false "Статус: open, resolved или dismissed"
+// @Param cursor query string false "Курсор следующей страницы"
+// @Param limit query int false "Размер страницы"
+// @Success 200 {object} reportListResponse
+// @Failure 400 {object} ErrorStruct
+// @Failure 403 {object} ErrorStruct
+// @Router /admin/reports [get]
+// @Security Bearer
+func (h *Handler) adminReportList(c *gin.Context) {
+	var req adminReportListRequest
+	if err := c.BindQuery(&req); err != nil {
+		validationErrorResponse(c, err)
+		return
+	}
+
+	list, err := h.services.Moderation.ListReports(c.Request.Context(), req.Status, req.Cursor, req.Limit)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) {
+			errorResponse(c, InvalidCursorCode)
+			return
+		}
+		h.logger.Error("failed to list reports",
+			"error", err,
+		)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	response := reportListResponse{
+		Items:      make([]reportResponse, 0, len(list.Reports)),
+		NextCursor: list.NextCursor,
+	}
+	for i := range list.Reports {
+		response.Items = append(response.Items, newReportResponse(&list.Reports[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+type adminReportResolveRequest struct {
+	Status string `json:"status" binding:"required,oneof=resolved dismissed"`
+}
+
+// @Summary Решение по жалобе
+// @Tags Admin
+// @Description Закрывает жалобу как решенную или отклоненную
+// @ModuleID Admin
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Идентификатор жалобы"
+// @Param input body adminReportResolveRequest true "Решение"
+// @Success 200 {object} reportResponse
+// @Failure 400 {object} ErrorStruct
+// @Failure 403 {object} ErrorStruct
+// @Router /admin/reports/{id}/resolve [post]
+// @Security Bearer
+func (h *Handler) adminReportResolve(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		h.logger.Error("failed to get user id", "error", err)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	reportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		errorResponse(c, ReportNotFoundCode)
+		return
+	}
+
+	var req adminReportResolveRequest
+	if err := c.BindJSON(&req); err != nil {
+		validationErrorResponse(c, err)
+		return
+	}
+
+	report, err := h.services.Moderation.ResolveReport(c.Request.Context(), adminID, reportID, req.Status)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrReportNotFound):
+			errorResponse(c, ReportNotFoundCode)
+		case errors.Is(err, service.ErrReportAlreadyResolved):
+			errorResponse(c, ReportAlreadyResolvedCode)
+		default:
+			h.logger.Error("failed to resolve report",
+				"error", err,
+			)
+			c.Status(http.StatusBadRequest)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, newReportResponse(report))
+}
+
+type adminUserSuspendRequest struct {
+	DurationHours int `json:"duration_hours" binding:"required,min=1,max=87600"`
+}
+
+type adminUserStatusResponse struct {
+	ID             uuid.UUID  `json:"id"`
+	Status         string     `json:"status"`
+	SuspendedUntil *time.Time `json:"suspended_until"`
+}
+
+func newAdminUserStatusResponse(u *domain.User) adminUserStatusResponse {
+	return adminUserStatusResponse{
+		ID:             u.ID,
+		Status:         u.Status,
+		SuspendedUntil: u.SuspendedUntil,
+	}
+}
+
+// @Summary Временная блокировка пользователя
+// @Tags Admin
+// @Description Блокирует пользователя на заданное количество часов
+// @ModuleID Admin
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Идентификатор пользователя"
+// @Param input body adminUserSuspendRequest true "Блокировка"
+// @Success 200 {object} adminUserStatusResponse
+// @Failure 400 {object} ErrorStruct
+// @Failure 403 {object} ErrorStruct
+// @Router /admin/users/{id}/suspend [post]
+// @Security Bearer
+func (h *Handler) adminUserSuspend(c *gin.Context) {
+	var req adminUserSuspendRequest
+	if err := c.BindJSON(&req); err != nil {
+		validationErrorResponse(c, err)
+		return
+	}
+
+	h.adminUserStatus(c, func(adminID, userID uuid.UUID) (*domain.User, error) {
+		return h.services.Moderation.SuspendUser(c.Request.Context(), adminID, userID, time.Duration(req.DurationHours)*time.Hour)
+	})
+}
+
+// @Summary Бессрочная блокировка пользователя
+// @Tags Admin
+// @Description Блокирует пользователя навсегда
+// @ModuleID Admin
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Идентификатор пользователя"
+// @Success 200 {object} adminUserStatusResponse
+// @Failure 400 {object} ErrorStruct
+// @Failure 403 {object} ErrorStruct
+// @Router /admin/users/{id}/ban [post]
+// @Security Bearer
+func (h *Handler) adminUserBan(c *gin.Context) {
+	h.adminUserStatus(c, func(adminID, userID uuid.UUID) (*domain.User, error) {
+		return h.services.Moderation.BanUser(c.Request.Context(), adminID, userID)
+	})
+}
+
+// @Summary Разблокировка пользователя
+// @Tags Admin
+// @Description Снимает временную или бессрочную блокировку
+// @ModuleID Admin
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Идентификатор пользователя"
+// @Success 200 {object} adminUserStatusResponse
+// @Failure 400 {object} ErrorStruct
+// @Failure 403 {object} ErrorStruct
+// @Router /admin/users/{id}/reinstate [post]
+// @Security Bearer
+func (h *Handler) adminUserReinstate(c *gin.Context) {
+	h.adminUserStatus(c, func(adminID, userID uuid.UUID) (*domain.User, error) {
+		return h.services.Moderation.ReinstateUser(c.Request.Context(), adminID, userID)
+	})
+}
+
+func (h *Handler) adminUserStatus(c *gin.Context, action func(adminID, userID uuid.UUID) (*domain.User, error)) {
+	adminID, userID, ok := h.adminUserIDs(c)
+	if !ok {
+		return
+	}
+
+	user, err := action(adminID, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			errorResponse(c, UserNotFoundCode)
+		case errors.Is(err, service.ErrModerateAdmin):
+			errorResponse(c, ModerateAdminCode)
+		default:
+			h.logger.Error("failed to change user status",
+				"error", err,
+			)
+			c.Status(http.StatusBadRequest)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, newAdminUserStatusResponse(user))
+}
+
+type adminUserListRequest struct {
+	cursorRequest
+	Search      string    `form:"q" binding:"omitempty,max=255"`
+	Role        string    `form:"role" binding:"omitempty,oneof=user admin"`
+	Status      string    `form:"status" binding:"omitempty,oneof=active suspended banned"`
+	CreatedFrom time.Time `form:"created_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedTo   time.Time `form:"created_to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+type adminUserResponse struct {
+	ID                    uuid.UUID  `json:"id"`
+	Username              string     `json:"username"`
+	Email                 string     `json:"email"`
+	Role                  string     `json:"role"`
+	Status                string     `json:"status"`
+	SuspendedUntil        *time.Time `json:"suspended_until"`
+	EmailVerifiedAt       *time.Time `json:"email_verified_at"`
+	PasswordResetRequired bool       `json:"password_reset_required"`
+	CreatedAt             time.Time  `json:"created_at"`
+}
+
+func newAdminUserResponse(u *domain.User) adminUserResponse {
+	return adminUserResponse{
+		ID:                    u.ID,
+		Username:              u.Username,
+		Email:                 u.Email,
+		Role:                  u.Role,
+		Status:                u.Status,
+		SuspendedUntil:        u.SuspendedUntil,
+		EmailVerifiedAt:       u.EmailVerifiedAt,
+		PasswordResetRequired: u.PasswordResetRequired,
+		CreatedAt:             u.CreatedAt,
+	}
+}
+
+type adminUserListResponse struct {
+	Items      []adminUserResponse `json:"items"`
+	NextCursor string              `json:"next_cursor"`
+}
+
+// @Summary Пользователи
+// @Tags Admin
+// @Description Поиск пользователей по email или имени с фильтрами
+// @ModuleID Admin
+// @Accept  json
+// @Produce  json
+// @Param q query string false "Часть email или имени пользователя"
+// @Param role query string false "Роль: user или admin"
+// @Param status query string false "Статус: active, suspended или banned"
+// @Param created_from query string false "Зарегистрирован не раньше (RFC 3339)"
+// @Param created_to query string false "Зарегистрирован раньше (RFC 3339)"
+// @Param cursor query string false "Курсор следующей страницы"
+// @Param limit query int false "Размер страницы"
+// @Success 200 {object} adminUserListResponse
+// @Failure 400 {object} ErrorStruct
+// @Failure 403 {object} ErrorStruct
+// @Router /admin/users [get]
+// @Security Bearer
+func (h *Handler) adminUserList(c *gin.Context) {
+	var req adminUserListRequest
+	if err := c.BindQuery(&req); err != nil {
+		validationErrorResponse(c, err)
+		return
+	}
+
+	input := &service.ListUsersInput{
+		Search: req.Search,
+		Role:   req.Role,
+		Status: req.Status,
+		Cursor: req.Cursor,
+		Limit:  req.Limit,
+	}
+	if !req.CreatedFrom.IsZero() {
+		input.CreatedFrom = &req.CreatedFrom
+	}
+	if !req.CreatedTo.IsZero() {
+		input.CreatedTo = &req.CreatedTo
+	}
+
+	list, err := h.services.UserAdmin.ListUsers(c.Request.Context(), input)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) {
+			errorResponse(c, InvalidCursorCode)
+			return
+		}
+		h.logger.Error("failed to list users",
+			"error", err,
+		)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	response := adminUserListResponse{
+		Items:      make([]adminUserResponse, 0, len(list.Users)),
+		NextCursor: list.NextCursor,
+	}
+	for i := range list.Users {
+		response.Items = append(response.Items, newAdminUserResponse(&list.Users[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+type adminUserChangeRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=user admin"`
+}
+
+// @Summary Смена роли пользователя
+// @Tags Admin
+// @Description Назначает пользователю роль, свою роль сменить нельзя
+// @ModuleID Admin
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Идентификатор пользователя"
+// @Param input body adminUserChangeRoleRequest true "Роль"
+// @Success 200 {object} adminUserResponse
+// @Failure 400 {object} ErrorStruct
+// @Failure 403 {object} ErrorStruct
+// @Router /admin/users/{id}/role [post]
+// @Security Bearer
+func (h *Handler) adminUserChangeRole(c *gin.Context) {
+	adminID, userID, ok := h.adminUserIDs(c)
+	if !ok {
+		return
+	}
+
+	var req adminUserChangeRoleRequest
+	if err := c.BindJSON(&req); err != nil {
+		validationErrorResponse(c, err)
+		return
+	}
+
+	user, err := h.services.UserAdmin.ChangeRole(c.Request.Context(), adminID, userID, req.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			errorResponse(c, UserNotFoundCode)
+		case errors.Is(err, service.ErrChangeOwnRole):
+			errorResponse(c, ChangeOwnRoleCode)
+		default:
+			h.logger.Error("failed to change user role",
+				"error", err,
+			)
+			c.Status(http.StatusBadRequest)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, newAdminUserResponse(user))
+}
+
+type adminUserPasswordResetResponse struct {
+	EmailSent bool `json:"email_sent"`
+}
+
+// @Summary Принудительный сброс пароля
+// @Tags Admin
+// @Description Блокирует вход до смены пароля и отправляет пользователю код сброса. Если письмо не отправилось, email_sent равен false, вход остается заблокированным, сброс можно повторить
+// @ModuleID Admin
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Идентификатор пользователя"
+// @Success 200 {object} adminUserPasswordResetResponse
+// @Failure 400 {object} ErrorStruct
+// @Failure 403 {object} ErrorStruct
+// @Router /admin/users/{id}/password-reset [post]
+// @Security Bearer
+func (h *Handler) adminUserPasswordReset(c *gin.Context) {
+	adminID, userID, ok := h.adminUserIDs(c)
+	if !ok {
+		return
+	}
+
+	emailSent, err := h.services.UserAdmin.ForcePasswordReset(c.Request.Context(), adminID, userID)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			errorResponse(c, UserNotFoundCode)
+			return
+		}
+		h.logger.Error("failed to force password reset",
+			"error", err,
+		)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, adminUserPasswordResetResponse{EmailSent: emailSent})
+}
+
+// @Summary Подтверждение email
+// @Tags Admin
+// @Description Отмечает email пользователя подтвержденным
+// @ModuleID Admin
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Идентификатор пользователя"
+// @Success 200 {object} adminUserResponse
+// @Failure 400 {object} ErrorStruct
+// @Failure 403 {object} ErrorStruct
+// @Router /admin/users/{id}/verify-email [post]
+// @Security Bearer
+func (h *Handler) adminUserVerifyEmail(c *gin.Context) {
+	adminID, userID, ok := h.adminUserIDs(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.services.UserAdmin.VerifyEmail(c.Request.Context(), adminID, userID)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			errorResponse(c, UserNotFoundCode)
+			return
+		}
+		h.logger.Error("failed to verify user email",
+			"error", err,
+		)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, newAdminUserResponse(user))
+}
+
+type adminUserImpersonateResponse struct {
+	AccessToken string    `json:"access_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// @Summary Вход под пользователем
+// @Tags Admin
+// @Description Выдает короткоживущий токен пользователя. Ответы на запросы с этим токеном содержат заголовок X-Impersonated-By, административные методы с ним недоступны
+// @ModuleID Admin
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Идентификатор пользователя"
+// @Success 200 {object} adminUserImpersonateResponse
+// @Failure 400 {object} ErrorStruct
+// @Failure 403 {object} ErrorStruct
+// @Router /admin/users/{id}/impersonate [post]
+// @Security Bearer
+func (h *Handler) adminUserImpersonate(c *gin.Context) {
+	adminID, userID, ok := h.adminUserIDs(c)
+	if !ok {
+		return
+	}
+
+	token, err := h.services.UserAdmin.Impersonate(c.Request.Context(), adminID, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			errorResponse(c, UserNotFoundCode)
+		case errors.Is(err, service.ErrImpersonateAdmin):
+			errorResponse(c, ImpersonateAdminCode)
+		default:
+			h.logger.Error("failed to impersonate user",
+				"error", err,
+			)
+			c.Status(http.StatusBadRequest)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, adminUserImpersonateResponse{
+		AccessToken: token.AccessToken,
+		ExpiresAt:   token.ExpiresAt,
+	})
+}
+
+// adminUserIDs returns the acting admin and the user from the path, it
+// writes the response itself when either is missing.
+func (h *Handler) adminUserIDs(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		h.logger.Error("failed to get user id", "error", err)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		errorResponse(c, UserNotFoundCode)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return adminID, userID, true
+}
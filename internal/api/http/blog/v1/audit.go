@@ -0,0 +1,205 @@
+package v1
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/newnorthblog/backend/internal/domain"
+	"github.com/newnorthblog/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const auditFormatCSV = "csv"
+
+var auditCSVHeader = []string{"id", "created_at", "action", "actor_id", "impersonator_id", "ip", "user_agent", "target_type", "target_id", "details"}
+
+type adminAuditListRequest struct {
+	cursorRequest
+	Action   string    `form:"action" binding:"omitempty,max=64"`
+	ActorID  string    `form:"actor_id" binding:"omitempty,uuid"`
+	TargetID string    `form:"target_id" binding:"omitempty,uuid"`
+	From     time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To       time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Format   string    `form:"format" binding:"omitempty,oneof=json csv"`
+}
+
+func (r *adminAuditListRequest) filter() *service.AuditFilterInput {
+	input := &service.AuditFilterInput{
+		Action: r.Action,
+	}
+	if id, err := uuid.Parse(r.ActorID); err == nil {
+		input.ActorID = &id
+	}
+	if id, err := uuid.Parse(r.TargetID); err == nil {
+		input.TargetID = &id
+	}
+	if !r.From.IsZero() {
+		input.From = &r.From
+	}
+	if !r.To.IsZero() {
+		input.To = &r.To
+	}
+
+	return input
+}
+
+type auditEventResponse struct {
+	ID             uuid.UUID       `json:"id"`
+	Action         string          `json:"action"`
+	ActorID        *uuid.UUID      `json:"actor_id"`
+	ImpersonatorID *uuid.UUID      `json:"impersonator_id"`
+	IP             string          `json:"ip"`
+	UserAgent      string          `json:"user_agent"`
+	TargetType     string          `json:"target_type"`
+	TargetID       *uuid.UUID      `json:"target_id"`
+	Details        json.RawMessage `json:"details" swaggertype:"object"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+func newAuditEventResponse(e *domain.AuditEvent) auditEventResponse {
+	return auditEventResponse{
+		ID:             e.ID,
+		Action:         e.Action,
+		ActorID:        e.ActorID,
+		ImpersonatorID: e.ImpersonatorID,
+		IP:             e.IP,
+		UserAgent:      e.UserAgent,
+		TargetType:     e.TargetType,
+		TargetID:       e.TargetID,
+		Details:        e.Details,
+		CreatedAt:      e.CreatedAt,
+	}
+}
+
+type auditEventListResponse struct {
+	Items      []auditEventResponse `json:"items"`
+	NextCursor string               `json:"next_cursor"`
+}
+
+// @Summary Журнал аудита
+// @Tags Admin
+// @Description Действия пользователей и администраторов. С format=csv возвращает все подходящие записи файлом CSV без постраничной разбивки
+// @ModuleID Admin
+// @Accept  json
+// @Produce  json
+// @Produce  text/csv
+// @Param action query string false "Действие, например user.login"
+// @Param actor_id query string false "Кто выполнил действие"
+// @Param target_id query string false "Над кем или чем выполнено действие"
+// @Param from query string false "Не раньше (RFC 3339)"
+// @Param to query string false "Раньше (RFC 3339)"
+// @Param format query string false "Формат: json или csv"
+// @Param cursor query string false "Курсор следующей страницы"
+// @Param limit query int false "Размер страницы"
+// @Success 200 {object} auditEventListResponse
+// @Failure 400 {object} ErrorStruct
+// @Failure 403 {object} ErrorStruct
+// @Router /admin/audit [get]
+// @Security Bearer
+func (h *Handler) adminAuditList(c *gin.Context) {
+	var req adminAuditListRequest
+	if err := c.BindQuery(&req); err != nil {
+		validationErrorResponse(c, err)
+		return
+	}
+
+	if req.Format == auditFormatCSV {
+		h.adminAuditExport(c, req.filter())
+		return
+	}
+
+	list, err := h.services.Audit.List(c.Request.Context(), req.filter(), req.Cursor, req.Limit)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) {
+			errorResponse(c, InvalidCursorCode)
+			return
+		}
+		h.logger.Error("failed to list audit events",
+			"error", err,
+		)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	response := auditEventListResponse{
+		Items:      make([]auditEventResponse, 0, len(list.Events)),
+		NextCursor: list.NextCursor,
+	}
+	for i := range list.Events {
+		response.Items = append(response.Items, newAuditEventResponse(&list.Events[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// adminAuditExport streams the events as CSV. Once the first row is written
+// the status is sent, so a later failure can only cut the file short.
+func (h *Handler) adminAuditExport(c *gin.Context, filter *service.AuditFilterInput) {
+	// a large export outlives the server write timeout
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		h.logger.Error("failed to reset write deadline", "error", err)
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="audit.csv"`)
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	if err := w.Write(auditCSVHeader); err != nil {
+		h.logger.Error("failed to write audit csv", "error", err)
+		return
+	}
+
+	err := h.services.Audit.Export(c.Request.Context(), filter, func(e *domain.AuditEvent) error {
+		record := []string{
+			e.ID.String(),
+			e.CreatedAt.UTC().Format(time.RFC3339),
+			e.Action,
+			uuidString(e.ActorID),
+			uuidString(e.ImpersonatorID),
+			e.IP,
+			e.UserAgent,
+			e.TargetType,
+			uuidString(e.TargetID),
+			string(e.Details),
+		}
+		for i := range record {
+			record[i] = csvSafe(record[i])
+		}
+
+		return w.Write(record)
+	})
+	w.Flush()
+	if err == nil {
+		err = w.Error()
+	}
+	if err != nil {
+		h.logger.Error("failed to export audit events",
+			"error", err,
+		)
+	}
+}
+
+func uuidString(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+
+	return id.String()
+}
+
+// csvSafe keeps spreadsheets from running values as formulas, every cell
+// goes through it since details carry client supplied values too.
+func csvSafe(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+
+	return s
+}
@@ -0,0 +1,182 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/newnorthblog/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) initAuthorRoutes(api *gin.RouterGroup) {
+	authors := api.Group("/authors/:username")
+	authors.POST("/follow", h.userIdentityMiddleware, h.authorFollow)
+	authors.DELETE("/follow", h.userIdentityMiddleware, h.authorUnfollow)
+	authors.GET("/followers", h.authorFollowers)
+	authors.GET("/following", h.authorFollowing)
+}
+
+// @Summary Подписка на автора
+// @Tags Authors
+// @Description Подписка на автора
+// @ModuleID Authors
+// @Accept  json
+// @Produce  json
+// @Param username path string true "Имя пользователя автора"
+// @Success 204
+// @Failure 400 {object} ErrorStruct
+// @Router /authors/{username}/follow [post]
+// @Security Bearer
+func (h *Handler) authorFollow(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		h.logger.Error("failed to get user id", "error", err)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.services.Follows.Follow(c.Request.Context(), userID, c.Param("username")); err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			errorResponse(c, UserNotFoundCode)
+			return
+		}
+		if errors.Is(err, service.ErrFollowSelf) {
+			errorResponse(c, FollowSelfCode)
+			return
+		}
+		h.logger.Error("failed to follow author",
+			"error", err,
+		)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Отписка от автора
+// @Tags Authors
+// @Description Отписка от автора
+// @ModuleID Authors
+// @Accept  json
+// @Produce  json
+// @Param username path string true "Имя пользователя автора"
+// @Success 204
+// @Failure 400 {object} ErrorStruct
+// @Router /authors/{username}/follow [delete]
+// @Security Bearer
+func (h *Handler) authorUnfollow(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		h.logger.Error("failed to get user id", "error", err)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.services.Follows.Unfollow(c.Request.Context(), userID, c.Param("username")); err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			errorResponse(c, UserNotFoundCode)
+			return
+		}
+		h.logger.Error("failed to unfollow author",
+			"error", err,
+		)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+type cursorRequest struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type followUserResponse struct {
+	ID         uuid.UUID `json:"id"`
+	Username   string    `json:"username"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+type followListResponse struct {
+	Items      []followUserResponse `json:"items"`
+	NextCursor string               `json:"next_cursor"`
+}
+
+// @Summary Подписчики автора
+// @Tags Authors
+// @Description Список подписчиков автора
+// @ModuleID Authors
+// @Accept  json
+// @Produce  json
+// @Param username path string true "Имя пользователя автора"
+// @Param cursor query string false "Курсор следующей страницы"
+// @Param limit query int false "Размер страницы"
+// @Success 200 {object} followListResponse
+// @Failure 400 {object} ErrorStruct
+// @Router /authors/{username}/followers [get]
+func (h *Handler) authorFollowers(c *gin.Context) {
+	h.followList(c, h.services.Follows.Followers)
+}
+
+// @Summary Подписки автора
+// @Tags Authors
+// @Description Список авторов, на которых подписан автор
+// @ModuleID Authors
+// @Accept  json
+// @Produce  json
+// @Param username path string true "Имя пользователя автора"
+// @Param cursor query string false "Курсор следующей страницы"
+// @Param limit query int false "Размер страницы"
+// @Success 200 {object} followListResponse
+// @Failure 400 {object} ErrorStruct
+// @Router /authors/{username}/following [get]
+func (h *Handler) authorFollowing(c *gin.Context) {
+	h.followList(c, h.services.Follows.Following)
+}
+
+type followListFunc func(ctx context.Context, username, cursor string, limit int) (*service.FollowList, error)
+
+func (h *Handler) followList(c *gin.Context, listFn followListFunc) {
+	var req cursorRequest
+	if err := c.BindQuery(&req); err != nil {
+		validationErrorResponse(c, err)
+		return
+	}
+
+	list, err := listFn(c.Request.Context(), c.Param("username"), req.Cursor, req.Limit)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			errorResponse(c, UserNotFoundCode)
+			return
+		}
+		if errors.Is(err, service.ErrInvalidCursor) {
+			errorResponse(c, InvalidCursorCode)
+			return
+		}
+		h.logger.Error("failed to list follows",
+			"error", err,
+		)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	response := followListResponse{
+		Items:      make([]followUserResponse, 0, len(list.Users)),
+		NextCursor: list.NextCursor,
+	}
+	for _, u := range list.Users {
+		response.Items = append(response.Items, followUserResponse{
+			ID:         u.ID,
+			Username:   u.Username,
+			FollowedAt: u.FollowedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -13,6 +13,77 @@ const (
 	UserRefreshTokenCookieNotFoundMessage = "user refresh token cookie not found"
 	UserRefreshTokenExpiredCode           = 1004
 	UserRefreshTokenExpiredMessage        = "user refresh token expired"
+	UserSuspendedCode                     = 1005
+	UserSuspendedMessage                  = "user suspended"
+	UserBannedCode                        = 1006
+	UserBannedMessage                     = "user banned"
+	AccessDeniedCode                      = 1007
+	AccessDeniedMessage                   = "access denied"
+	UserPasswordResetRequiredCode         = 1008
+	UserPasswordResetRequiredMessage      = "user password reset required"
+	PasswordResetTokenInvalidCode         = 1009
+	PasswordResetTokenInvalidMessage      = "invalid password reset token"
+	ChangeOwnRoleCode                     = 1010
+	ChangeOwnRoleMessage                  = "cannot change own role"
+	ImpersonateAdminCode                  = 1011
+	ImpersonateAdminMessage               = "cannot impersonate an admin"
+
+	MediaFileRequiredCode       = 2001
+	MediaFileRequiredMessage    = "media file required"
+	MediaTooLargeCode           = 2002
+	MediaTooLargeMessage        = "media too large"
+	MediaUnsupportedTypeCode    = 2003
+	MediaUnsupportedTypeMessage = "unsupported media type"
+	MediaNotFoundCode           = 2004
+	MediaNotFoundMessage        = "media not found"
+	MediaQueueFullCode          = 2005
+	MediaQueueFullMessage       = "media processing queue is full, try again later"
+
+	FollowSelfCode    = 3001
+	FollowSelfMessage = "cannot follow yourself"
+
+	NotificationUnknownKindCode           = 4001
+	NotificationUnknownKindMessage        = "unknown notification kind"
+	NotificationUnknownFrequencyCode      = 4002
+	NotificationUnknownFrequencyMessage   = "unknown notification frequency"
+	NotificationInvalidUnsubscribeCode    = 4003
+	NotificationInvalidUnsubscribeMessage = "invalid unsubscribe token"
+
+	ReportAlreadyExistsCode        = 5001
+	ReportAlreadyExistsMessage     = "report already exists"
+	ReportNotFoundCode             = 5002
+	ReportNotFoundMessage          = "report not found"
+	ReportAlreadyResolvedCode      = 5003
+	ReportAlreadyResolvedMessage   = "report already resolved"
+	ReportUnsupportedTargetCode    = 5004
+	ReportUnsupportedTargetMessage = "unsupported report target"
+	ReportSelfCode                 = 5005
+	ReportSelfMessage              = "cannot report yourself"
+	ModerateAdminCode              = 5006
+	ModerateAdminMessage           = "cannot moderate an admin"
+
+	InvalidCursorCode    = 6001
+	InvalidCursorMessage = "invalid cursor"
+
+	NewsletterInvalidSubscriptionCode    = 7001
+	NewsletterInvalidSubscriptionMessage = "invalid subscription token"
+	NewsletterInvalidUnsubscribeCode     = 7002
+	NewsletterInvalidUnsubscribeMessage  = "invalid unsubscribe token"
+	NewsletterUnknownEventCode           = 7003
+	NewsletterUnknownEventMessage        = "unknown newsletter event"
+	NewsletterIssueNotFoundCode          = 7004
+	NewsletterIssueNotFoundMessage       = "newsletter issue not found"
+	NewsletterIssueAlreadySentCode       = 7005
+	NewsletterIssueAlreadySentMessage    = "newsletter issue already sent"
+
+	WebhookUnknownEventCode        = 8001
+	WebhookUnknownEventMessage     = "unknown webhook event"
+	WebhookNotFoundCode            = 8002
+	WebhookNotFoundMessage         = "webhook not found"
+	WebhookDeliveryNotFoundCode    = 8003
+	WebhookDeliveryNotFoundMessage = "webhook delivery not found"
+	WebhookInvalidURLCode          = 8004
+	WebhookInvalidURLMessage       = "webhook url must be http or https and not point to a private address"
 )
 
 type ErrorCode int
@@ -53,6 +124,102 @@ func getErrorStruct(code ErrorCode) *ErrorStruct {
 	case UserRefreshTokenExpiredCode:
 		errorStruct.ErrorCode = UserRefreshTokenExpiredCode
 		errorStruct.ErrorMessage = UserRefreshTokenExpiredMessage
+	case UserSuspendedCode:
+		errorStruct.ErrorCode = UserSuspendedCode
+		errorStruct.ErrorMessage = UserSuspendedMessage
+	case UserBannedCode:
+		errorStruct.ErrorCode = UserBannedCode
+		errorStruct.ErrorMessage = UserBannedMessage
+	case AccessDeniedCode:
+		errorStruct.ErrorCode = AccessDeniedCode
+		errorStruct.ErrorMessage = AccessDeniedMessage
+	case UserPasswordResetRequiredCode:
+		errorStruct.ErrorCode = UserPasswordResetRequiredCode
+		errorStruct.ErrorMessage = UserPasswordResetRequiredMessage
+	case PasswordResetTokenInvalidCode:
+		errorStruct.ErrorCode = PasswordResetTokenInvalidCode
+		errorStruct.ErrorMessage = PasswordResetTokenInvalidMessage
+	case ChangeOwnRoleCode:
+		errorStruct.ErrorCode = ChangeOwnRoleCode
+		errorStruct.ErrorMessage = ChangeOwnRoleMessage
+	case ImpersonateAdminCode:
+		errorStruct.ErrorCode = ImpersonateAdminCode
+		errorStruct.ErrorMessage = ImpersonateAdminMessage
+	case MediaFileRequiredCode:
+		errorStruct.ErrorCode = MediaFileRequiredCode
+		errorStruct.ErrorMessage = MediaFileRequiredMessage
+	case MediaTooLargeCode:
+		errorStruct.ErrorCode = MediaTooLargeCode
+		errorStruct.ErrorMessage = MediaTooLargeMessage
+	case MediaUnsupportedTypeCode:
+		errorStruct.ErrorCode = MediaUnsupportedTypeCode
+		errorStruct.ErrorMessage = MediaUnsupportedTypeMessage
+	case MediaNotFoundCode:
+		errorStruct.ErrorCode = MediaNotFoundCode
+		errorStruct.ErrorMessage = MediaNotFoundMessage
+	case MediaQueueFullCode:
+		errorStruct.ErrorCode = MediaQueueFullCode
+		errorStruct.ErrorMessage = MediaQueueFullMessage
+	case FollowSelfCode:
+		errorStruct.ErrorCode = FollowSelfCode
+		errorStruct.ErrorMessage = FollowSelfMessage
+	case NotificationUnknownKindCode:
+		errorStruct.ErrorCode = NotificationUnknownKindCode
+		errorStruct.ErrorMessage = NotificationUnknownKindMessage
+	case NotificationUnknownFrequencyCode:
+		errorStruct.ErrorCode = NotificationUnknownFrequencyCode
+		errorStruct.ErrorMessage = NotificationUnknownFrequencyMessage
+	case NotificationInvalidUnsubscribeCode:
+		errorStruct.ErrorCode = NotificationInvalidUnsubscribeCode
+		errorStruct.ErrorMessage = NotificationInvalidUnsubscribeMessage
+	case ReportAlreadyExistsCode:
+		errorStruct.ErrorCode = ReportAlreadyExistsCode
+		errorStruct.ErrorMessage = ReportAlreadyExistsMessage
+	case ReportNotFoundCode:
+		errorStruct.ErrorCode = ReportNotFoundCode
+		errorStruct.ErrorMessage = ReportNotFoundMessage
+	case ReportAlreadyResolvedCode:
+		errorStruct.ErrorCode = ReportAlreadyResolvedCode
+		errorStruct.ErrorMessage = ReportAlreadyResolvedMessage
+	case ReportUnsupportedTargetCode:
+		errorStruct.ErrorCode = ReportUnsupportedTargetCode
+		errorStruct.ErrorMessage = ReportUnsupportedTargetMessage
+	case ReportSelfCode:
+		errorStruct.ErrorCode = ReportSelfCode
+		errorStruct.ErrorMessage = ReportSelfMessage
+	case ModerateAdminCode:
+		errorStruct.ErrorCode = ModerateAdminCode
+		errorStruct.ErrorMessage = ModerateAdminMessage
+	case InvalidCursorCode:
+		errorStruct.ErrorCode = InvalidCursorCode
+		errorStruct.ErrorMessage = InvalidCursorMessage
+	case NewsletterInvalidSubscriptionCode:
+		errorStruct.ErrorCode = NewsletterInvalidSubscriptionCode
+		errorStruct.ErrorMessage = NewsletterInvalidSubscriptionMessage
+	case NewsletterInvalidUnsubscribeCode:
+		errorStruct.ErrorCode = NewsletterInvalidUnsubscribeCode
+		errorStruct.ErrorMessage = NewsletterInvalidUnsubscribeMessage
+	case NewsletterUnknownEventCode:
+		errorStruct.ErrorCode = NewsletterUnknownEventCode
+		errorStruct.ErrorMessage = NewsletterUnknownEventMessage
+	case NewsletterIssueNotFoundCode:
+		errorStruct.ErrorCode = NewsletterIssueNotFoundCode
+		errorStruct.ErrorMessage = NewsletterIssueNotFoundMessage
+	case NewsletterIssueAlreadySentCode:
+		errorStruct.ErrorCode = NewsletterIssueAlreadySentCode
+		errorStruct.ErrorMessage = NewsletterIssueAlreadySentMessage
+	case WebhookUnknownEventCode:
+		errorStruct.ErrorCode = WebhookUnknownEventCode
+		errorStruct.ErrorMessage = WebhookUnknownEventMessage
+	case WebhookNotFoundCode:
+		errorStruct.ErrorCode = WebhookNotFoundCode
+		errorStruct.ErrorMessage = WebhookNotFoundMessage
+	case WebhookDeliveryNotFoundCode:
+		errorStruct.ErrorCode = WebhookDeliveryNotFoundCode
+		errorStruct.ErrorMessage = WebhookDeliveryNotFoundMessage
+	case WebhookInvalidURLCode:
+		errorStruct.ErrorCode = WebhookInvalidURLCode
+		errorStruct.ErrorMessage = WebhookInvalidURLMessage
 	}
 
 	return errorStruct
@@ -36,6 +36,14 @@ func NewHandler(
 }
 
 func (h *Handler) Init(api *gin.RouterGroup) {
-	v1 := api.Group("v1")
+	v1 := api.Group("v1", h.clientMiddleware)
 	h.initUserRoutes(v1)
+	h.initMediaRoutes(v1)
+	h.initAuthorRoutes(v1)
+	h.initNotificationRoutes(v1)
+	h.initNotificationSettingRoutes(v1)
+	h.initReportRoutes(v1)
+	h.initAdminRoutes(v1)
+	h.initNewsletterRoutes(v1)
+	h.initStreamRoutes(v1)
 }
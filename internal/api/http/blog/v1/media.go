@@ -0,0 +1,176 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/newnorthblog/backend/internal/domain"
+	"github.com/newnorthblog/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) initMediaRoutes(api *gin.RouterGroup) {
+	media := api.Group("/media", h.userIdentityMiddleware)
+	media.POST("", h.mediaUpload)
+	media.GET("/:id", h.mediaGet)
+}
+
+type mediaResponse struct {
+	ID           uuid.UUID              `json:"id"`
+	URL          string                 `json:"url"`
+	Status       string                 `json:"status"`
+	OriginalName string                 `json:"original_name"`
+	MimeType     string                 `json:"mime_type"`
+	Size         int64                  `json:"size"`
+	Width        int                    `json:"width"`
+	Height       int                    `json:"height"`
+	Variants     []mediaVariantResponse `json:"variants"`
+	Srcset       string                 `json:"srcset"`
+	CreatedAt    time.Time              `json:"created_at"`
+}
+
+type mediaVariantResponse struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// @Summary Загрузка файла
+// @Tags Media
+// @Description Загрузка изображения. Метаданные удаляются, адаптивные варианты создаются асинхронно (для GIF вариантов нет, анимация сохраняется) и доступны после перехода в статус ready, статус можно узнать через GET /media/{id}
+// @ModuleID Media
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param file formData file true "Файл"
+// @Success 201 {object} mediaResponse
+// @Failure 400 {object} ErrorStruct
+// @Failure 503 {object} ErrorStruct
+// @Router /media [post]
+// @Security Bearer
+func (h *Handler) mediaUpload(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		h.logger.Error("failed to get user id", "error", err)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		errorResponse(c, MediaFileRequiredCode)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		h.logger.Error("failed to open uploaded file", "error", err)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	media, err := h.services.Media.Upload(c.Request.Context(), &service.UploadMediaInput{
+		UserID:       userID,
+		OriginalName: fileHeader.Filename,
+		Size:         fileHeader.Size,
+		File:         file,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrMediaTooLarge) {
+			errorResponse(c, MediaTooLargeCode)
+			return
+		}
+		if errors.Is(err, service.ErrMediaUnsupportedType) {
+			errorResponse(c, MediaUnsupportedTypeCode)
+			return
+		}
+		if errors.Is(err, service.ErrMediaQueueFull) {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, getErrorStruct(MediaQueueFullCode))
+			return
+		}
+		h.logger.Error("failed to upload media",
+			"error", err,
+		)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newMediaResponse(media))
+}
+
+// @Summary Файл
+// @Tags Media
+// @Description Файл, загруженный текущим пользователем, со статусом обработки
+// @ModuleID Media
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Идентификатор файла"
+// @Success 200 {object} mediaResponse
+// @Failure 400 {object} ErrorStruct
+// @Router /media/{id} [get]
+// @Security Bearer
+func (h *Handler) mediaGet(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		h.logger.Error("failed to get user id", "error", err)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	mediaID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		errorResponse(c, MediaNotFoundCode)
+		return
+	}
+
+	media, err := h.services.Media.Get(c.Request.Context(), userID, mediaID)
+	if err != nil {
+		if errors.Is(err, service.ErrMediaNotFound) {
+			errorResponse(c, MediaNotFoundCode)
+			return
+		}
+		h.logger.Error("failed to get media",
+			"error", err,
+		)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, newMediaResponse(media))
+}
+
+func newMediaResponse(media *domain.Media) mediaResponse {
+	response := mediaResponse{
+		ID:           media.ID,
+		URL:          media.URL,
+		Status:       media.Status,
+		OriginalName: media.OriginalName,
+		MimeType:     media.MimeType,
+		Size:         media.Size,
+		Width:        media.Width,
+		Height:       media.Height,
+		Variants:     make([]mediaVariantResponse, 0, len(media.Variants)),
+		CreatedAt:    media.CreatedAt,
+	}
+
+	srcset := make([]string, 0, len(media.Variants)+1)
+	for _, v := range media.Variants {
+		response.Variants = append(response.Variants, mediaVariantResponse{
+			URL:    v.URL,
+			Width:  v.Width,
+			Height: v.Height,
+		})
+		srcset = append(srcset, fmt.Sprintf("%s %dw", v.URL, v.Width))
+	}
+	if media.Width > 0 {
+		srcset = append(srcset, fmt.Sprintf("%s %dw", media.URL, media.Width))
+	}
+	response.Srcset = strings.Join(srcset, ", ")
+
+	return response
+}
@@ -6,42 +6,160 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/newnorthblog/backend/internal/domain"
+	"github.com/newnorthblog/backend/internal/pkg/tokenmanager"
+	"github.com/newnorthblog/backend/internal/service"
+
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 const (
 	authorizationHeader = "Authorization"
 	userCtx             = "userId"
+	userRoleCtx         = "userRole"
+	impersonatorCtx     = "impersonatorId"
+
+	// impersonatedByHeader marks every response of an impersonation session.
+	impersonatedByHeader = "X-Impersonated-By"
 )
 
+// clientMiddleware passes the client address and user agent to the services
+// for the audit log.
+func (h *Handler) clientMiddleware(c *gin.Context) {
+	ctx := service.WithClient(c.Request.Context(), service.Client{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+	c.Request = c.Request.WithContext(ctx)
+}
+
 func (h *Handler) userIdentityMiddleware(c *gin.Context) {
-	id, err := h.parseAuthHeader(c)
+	claims, err := h.parseAuthHeader(c)
 	if err != nil {
 		if !errors.Is(err, jwt.ErrTokenExpired) {
 			h.logger.Error("parse auth header failed", "error", err)
 		}
 		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		h.logger.Error("parse user id failed", "error", err)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	// a still valid token must not let a suspended or banned user in
+	user, err := h.services.Users.GetActive(c.Request.Context(), userID)
+	if err != nil {
+		h.abortInactiveUser(c, err)
+		return
+	}
+
+	if claims.ImpersonatorID != "" {
+		adminID, ok := h.checkImpersonator(c, claims.ImpersonatorID)
+		if !ok {
+			return
+		}
+		// the audit log has to name the admin, not only the impersonated user
+		c.Request = c.Request.WithContext(service.WithImpersonator(c.Request.Context(), adminID))
+		c.Set(impersonatorCtx, claims.ImpersonatorID)
+		c.Header(impersonatedByHeader, claims.ImpersonatorID)
+	}
+
+	c.Set(userCtx, claims.Subject)
+	c.Set(userRoleCtx, user.Role)
+}
+
+// checkImpersonator makes an impersonation token stop working as soon as the
+// admin who issued it is no longer an active admin, it returns the admin id.
+func (h *Handler) checkImpersonator(c *gin.Context, id string) (uuid.UUID, bool) {
+	adminID, err := uuid.Parse(id)
+	if err != nil {
+		h.logger.Error("parse impersonator id failed", "error", err)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return uuid.Nil, false
+	}
+
+	admin, err := h.services.Users.GetActive(c.Request.Context(), adminID)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) ||
+			errors.Is(err, service.ErrUserSuspended) ||
+			errors.Is(err, service.ErrUserBanned) ||
+			errors.Is(err, service.ErrUserPasswordResetRequired) {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return uuid.Nil, false
+		}
+		h.logger.Error("get active impersonator failed", "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return uuid.Nil, false
+	}
+
+	if admin.Role != domain.UserRoleAdmin {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return uuid.Nil, false
+	}
+
+	return adminID, true
+}
+
+func (h *Handler) abortInactiveUser(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		c.AbortWithStatus(http.StatusUnauthorized)
+	case errors.Is(err, service.ErrUserSuspended):
+		c.AbortWithStatusJSON(http.StatusForbidden, getErrorStruct(UserSuspendedCode))
+	case errors.Is(err, service.ErrUserBanned):
+		c.AbortWithStatusJSON(http.StatusForbidden, getErrorStruct(UserBannedCode))
+	case errors.Is(err, service.ErrUserPasswordResetRequired):
+		c.AbortWithStatusJSON(http.StatusForbidden, getErrorStruct(UserPasswordResetRequiredCode))
+	default:
+		h.logger.Error("get active user failed", "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
 	}
+}
 
-	c.Set(userCtx, id)
+// adminMiddleware must run after userIdentityMiddleware.
+func (h *Handler) adminMiddleware(c *gin.Context) {
+	// an impersonation session never gets admin rights
+	if c.GetString(userRoleCtx) != domain.UserRoleAdmin || c.GetString(impersonatorCtx) != "" {
+		c.AbortWithStatusJSON(http.StatusForbidden, getErrorStruct(AccessDeniedCode))
+		return
+	}
 }
 
-func (h *Handler) parseAuthHeader(c *gin.Context) (string, error) {
+func (h *Handler) parseAuthHeader(c *gin.Context) (*tokenmanager.Claims, error) {
 	header := c.GetHeader(authorizationHeader)
 	slog.String("header", header)
 	if header == "" {
-		return "", errors.New("empty auth header")
+		return nil, errors.New("empty auth header")
 	}
 
 	headerParts := strings.Split(header, " ")
 	if len(headerParts) != 2 || headerParts[0] != "Bearer" {
-		return "", errors.New("invalid auth header")
+		return nil, errors.New("invalid auth header")
 	}
 
 	if len(headerParts[1]) == 0 {
-		return "", errors.New("token is empty")
+		return nil, errors.New("token is empty")
+	}
+
+	return h.tokenManager.ParseClaims(headerParts[1])
+}
+
+func getUserID(c *gin.Context) (uuid.UUID, error) {
+	id, ok := c.Get(userCtx)
+	if !ok {
+		return uuid.Nil, errors.New("user id not found in context")
+	}
+
+	idStr, ok := id.(string)
+	if !ok {
+		return uuid.Nil, errors.New("user id is of invalid type")
 	}
 
-	return h.tokenManager.Parse(headerParts[1])
+	return uuid.Parse(idStr)
 }
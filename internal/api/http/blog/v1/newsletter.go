@@ -0,0 +1,363 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/newnorthblog/backend/internal/domain"
+	"github.com/newnorthblog/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+)
+
+const webhookSecretHeader = "X-Webhook-Secret"
+
+func (h *Handler) initNewsletterRoutes(api *gin.RouterGroup) {
+	newsletter := api.Group("/newsletter")
+	newsletter.POST("/subscribe", h.newsletterSubscribe)
+	newsletter.GET("/confirm", h.newsletterConfirm)
+	// unsubscribe links from emails, authenticated by the token: GET only asks
+	// to confirm, POST unsubscribes and also serves one-click requests (RFC 8058)
+	newsletter.GET("/unsubscribe", h.newsletterUnsubscribeConfirm)
+	newsletter.POST("/unsubscribe", h.newsletterUnsubscribe)
+	newsletter.POST("/webhooks/events", h.newsletterEvent)
+}
+
+type newsletterSubscribeRequest struct {
+	Email string `json:"email" binding:"required,email,max=255"`
+}
+
+// @Summary Подписка на рассылку
+// @Tags Newsletter
+// @Description Отправляет письмо со ссылкой подтверждения. Ответ не зависит от того, подписан ли адрес
+// @ModuleID Newsletter
+// @Accept  json
+// @Produce  json
+// @Param input body newsletterSubscribeRequest true "Подписка"
+// @Success 202
+// @Failure 400 {object} ErrorStruct
+// @Router /newsletter/subscribe [post]
+func (h *Handler) newsletterSubscribe(c *gin.Context) {
+	var req newsletterSubscribeRequest
+	if err := c.BindJSON(&req); err != nil {
+		validationErrorResponse(c, err)
+		return
+	}
+
+	if err := h.services.Newsletter.Subscribe(c.Request.Context(), req.Email); err != nil {
+		h.logger.Error("failed to subscribe to newsletter",
+			"error", err,
+		)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+type newsletterTokenRequest struct {
+	Token string `form:"token" binding:"required"`
+}
+
+// @Summary Подтверждение подписки на рассылку
+// @Tags Newsletter
+// @Description Подтверждает адрес по ссылке из письма
+// @ModuleID Newsletter
+// @Accept  json
+// @Produce  json
+// @Param token query string true "Токен подтверждения"
+// @Success 204
+// @Failure 400 {object} ErrorStruct
+// @Router /newsletter/confirm [get]
+func (h *Handler) newsletterConfirm(c *gin.Context) {
+	var req newsletterTokenRequest
+	if err := c.BindQuery(&req); err != nil {
+		validationErrorResponse(c, err)
+		return
+	}
+
+	if err := h.services.Newsletter.Confirm(c.Request.Context(), req.Token); err != nil {
+		if errors.Is(err, service.ErrInvalidSubscriptionToken) {
+			errorResponse(c, NewsletterInvalidSubscriptionCode)
+			return
+		}
+		h.logger.Error("failed to confirm newsletter subscription",
+			"error", err,
+		)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Подтверждение отписки от рассылки
+// @Tags Newsletter
+// @Description Страница по ссылке из письма с формой подтверждения отписки, сама ничего не меняет
+// @ModuleID Newsletter
+// @Produce  html
+// @Param token query string true "Токен отписки"
+// @Success 200
+// @Failure 400 {object} ErrorStruct
+// @Router /newsletter/unsubscribe [get]
+func (h *Handler) newsletterUnsubscribeConfirm(c *gin.Context) {
+	var req newsletterTokenRequest
+	if err := c.BindQuery(&req); err != nil {
+		validationErrorResponse(c, err)
+		return
+	}
+
+	h.unsubscribeConfirm(c, "Отписка от рассылки", "Отписаться от рассылки New North?", req.Token)
+}
+
+// @Summary Отписка от рассылки
+// @Tags Newsletter
+// @Description Отписывает адрес от рассылки. Токен передается в форме или, для отписки в один клик (RFC 8058), в строке запроса
+// @ModuleID Newsletter
+// @Accept  x-www-form-urlencoded
+// @Produce  json
+// @Param token formData string true "Токен отписки"
+// @Success 204
+// @Failure 400 {object} ErrorStruct
+// @Router /newsletter/unsubscribe [post]
+func (h *Handler) newsletterUnsubscribe(c *gin.Context) {
+	// the form binding reads the body and the query, one-click clients post
+	// to the link from List-Unsubscribe with the token in the query
+	var req newsletterTokenRequest
+	if err := c.MustBindWith(&req, binding.Form); err != nil {
+		validationErrorResponse(c, err)
+		return
+	}
+
+	if err := h.services.Newsletter.Unsubscribe(c.Request.Context(), req.Token); err != nil {
+		if errors.Is(err, service.ErrInvalidUnsubscribeToken) {
+			errorResponse(c, NewsletterInvalidUnsubscribeCode)
+			return
+		}
+		h.logger.Error("failed to unsubscribe from newsletter",
+			"error", err,
+		)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+type newsletterEventRequest struct {
+	Type      string `json:"type" binding:"required"`
+	Email     string `json:"email" binding:"required"`
+	Permanent bool   `json:"permanent"`
+}
+
+// @Summary Событие почтового провайдера
+// @Tags Newsletter
+// @Description Вебхук для недоставленных писем (bounce) и жалоб (complaint). Жалобы и постоянные ошибки доставки отключают адрес. Пока не задан NEWSLETTER_WEBHOOK_SECRET, все события отклоняются
+// @ModuleID Newsletter
+// @Accept  json
+// @Produce  json
+// @Param X-Webhook-Secret header string true "Секретный ключ вебхука"
+// @Param input body newsletterEventRequest true "Событие"
+// @Success 204
+// @Failure 400 {object} ErrorStruct
+// @Failure 401
+// @Router /newsletter/webhooks/events [post]
+func (h *Handler) newsletterEvent(c *gin.Context) {
+	var req newsletterEventRequest
+	if err := c.BindJSON(&req); err != nil {
+		validationErrorResponse(c, err)
+		return
+	}
+
+	if err := h.services.Newsletter.HandleEvent(c.Request.Context(), c.GetHeader(webhookSecretHeader), &service.NewsletterEventInput{
+		Type:      req.Type,
+		Email:     req.Email,
+		Permanent: req.Permanent,
+	}); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidWebhookSecret):
+			c.AbortWithStatus(http.StatusUnauthorized)
+		case errors.Is(err, service.ErrUnknownNewsletterEvent):
+			errorResponse(c, NewsletterUnknownEventCode)
+		default:
+			h.logger.Error("failed to handle newsletter event",
+				"error", err,
+			)
+			c.Status(http.StatusBadRequest)
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+type newsletterIssueCreateRequest struct {
+	Subject string `json:"subject" binding:"required,max=255"`
+	Text    string `json:"text" binding:"required"`
+	HTML    string `json:"html"`
+}
+
+type newsletterIssueResponse struct {
+	ID            uuid.UUID  `json:"id"`
+	Subject       string     `json:"subject"`
+	Text          string     `json:"text"`
+	HTML          string     `json:"html"`
+	Status        string     `json:"status"`
+	CreatedBy     *uuid.UUID `json:"created_by"`
+	SendStartedAt *time.Time `json:"send_started_at"`
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+func newNewsletterIssueResponse(i *domain.NewsletterIssue) newsletterIssueResponse {
+	return newsletterIssueResponse{
+		ID:            i.ID,
+		Subject:       i.Subject,
+		Text:          i.Text,
+		HTML:          i.HTML,
+		Status:        i.Status,
+		CreatedBy:     i.CreatedBy,
+		SendStartedAt: i.SendStartedAt,
+		SentAt:        i.SentAt,
+		CreatedAt:     i.CreatedAt,
+	}
+}
+
+type newsletterIssueListResponse struct {
+	Items      []newsletterIssueResponse `json:"items"`
+	NextCursor string                    `json:"next_cursor"`
+}
+
+// @Summary Новый выпуск рассылки
+// @Tags Admin
+// @Description Создает черновик выпуска. Если html не задан, письмо строится из текста
+// @ModuleID Admin
+// @Accept  json
+// @Produce  json
+// @Param input body newsletterIssueCreateRequest true "Выпуск"
+// @Success 201 {object} newsletterIssueResponse
+// @Failure 400 {object} ErrorStruct
+// @Failure 403 {object} ErrorStruct
+// @Router /admin/newsletter/issues [post]
+// @Security Bearer
+func (h *Handler) adminNewsletterIssueCreate(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		h.logger.Error("failed to get user id", "error", err)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	var req newsletterIssueCreateRequest
+	if err := c.BindJSON(&req); err != nil {
+		validationErrorResponse(c, err)
+		return
+	}
+
+	issue, err := h.services.Newsletter.CreateIssue(c.Request.Context(), adminID, &service.NewsletterIssueInput{
+		Subject: req.Subject,
+		Text:    req.Text,
+		HTML:    req.HTML,
+	})
+	if err != nil {
+		h.logger.Error("failed to create newsletter issue",
+			"error", err,
+		)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newNewsletterIssueResponse(issue))
+}
+
+// @Summary Выпуски рассылки
+// @Tags Admin
+// @Description Список выпусков рассылки
+// @ModuleID Admin
+// @Accept  json
+// @Produce  json
+// @Param cursor query string false "Курсор следующей страницы"
+// @Param limit query int false "Размер страницы"
+// @Success 200 {object} newsletterIssueListResponse
+// @Failure 400 {object} ErrorStruct
+// @Failure 403 {object} ErrorStruct
+// @Router /admin/newsletter/issues [get]
+// @Security Bearer
+func (h *Handler) adminNewsletterIssueList(c *gin.Context) {
+	var req cursorRequest
+	if err := c.BindQuery(&req); err != nil {
+		validationErrorResponse(c, err)
+		return
+	}
+
+	list, err := h.services.Newsletter.ListIssues(c.Request.Context(), req.Cursor, req.Limit)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) {
+			errorResponse(c, InvalidCursorCode)
+			return
+		}
+		h.logger.Error("failed to list newsletter issues",
+			"error", err,
+		)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	response := newsletterIssueListResponse{
+		Items:      make([]newsletterIssueResponse, 0, len(list.Issues)),
+		NextCursor: list.NextCursor,
+	}
+	for i := range list.Issues {
+		response.Items = append(response.Items, newNewsletterIssueResponse(&list.Issues[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Отправка выпуска рассылки
+// @Tags Admin
+// @Description Ставит черновик в очередь, письма отправляются подтвержденным подписчикам партиями
+// @ModuleID Admin
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Идентификатор выпуска"
+// @Success 202 {object} newsletterIssueResponse
+// @Failure 400 {object} ErrorStruct
+// @Failure 403 {object} ErrorStruct
+// @Router /admin/newsletter/issues/{id}/send [post]
+// @Security Bearer
+func (h *Handler) adminNewsletterIssueSend(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		h.logger.Error("failed to get user id", "error", err)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	issueID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		errorResponse(c, NewsletterIssueNotFoundCode)
+		return
+	}
+
+	issue, err := h.services.Newsletter.SendIssue(c.Request.Context(), adminID, issueID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNewsletterIssueNotFound):
+			errorResponse(c, NewsletterIssueNotFoundCode)
+		case errors.Is(err, service.ErrNewsletterIssueAlreadySent):
+			errorResponse(c, NewsletterIssueAlreadySentCode)
+		default:
+			h.logger.Error("failed to send newsletter issue",
+				"error", err,
+			)
+			c.Status(http.StatusBadRequest)
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, newNewsletterIssueResponse(issue))
+}
@@ -0,0 +1,133 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/newnorthblog/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) initNotificationRoutes(api *gin.RouterGroup) {
+	notifications := api.Group("/users/me/notifications", h.userIdentityMiddleware)
+	notifications.GET("", h.notificationList)
+	notifications.POST("/read", h.notificationMarkRead)
+}
+
+type notificationResponse struct {
+	ID            uuid.UUID  `json:"id"`
+	Kind          string     `json:"kind"`
+	ActorID       *uuid.UUID `json:"actor_id"`
+	ActorUsername *string    `json:"actor_username"`
+	TargetID      *uuid.UUID `json:"target_id"`
+	ReadAt        *time.Time `json:"read_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type notificationListResponse struct {
+	Items       []notificationResponse `json:"items"`
+	UnreadCount int                    `json:"unread_count"`
+	NextCursor  string                 `json:"next_cursor"`
+}
+
+// @Summary Уведомления
+// @Tags Notifications
+// @Description Список уведомлений текущего пользователя и количество непрочитанных
+// @ModuleID Notifications
+// @Accept  json
+// @Produce  json
+// @Param cursor query string false "Курсор следующей страницы"
+// @Param limit query int false "Размер страницы"
+// @Success 200 {object} notificationListResponse
+// @Failure 400 {object} ErrorStruct
+// @Router /users/me/notifications [get]
+// @Security Bearer
+func (h *Handler) notificationList(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		h.logger.Error("failed to get user id", "error", err)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	var req cursorRequest
+	if err := c.BindQuery(&req); err != nil {
+		validationErrorResponse(c, err)
+		return
+	}
+
+	list, err := h.services.Notifications.List(c.Request.Context(), userID, req.Cursor, req.Limit)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) {
+			errorResponse(c, InvalidCursorCode)
+			return
+		}
+		h.logger.Error("failed to list notifications",
+			"error", err,
+		)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	response := notificationListResponse{
+		Items:       make([]notificationResponse, 0, len(list.Notifications)),
+		UnreadCount: list.UnreadCount,
+		NextCursor:  list.NextCursor,
+	}
+	for _, n := range list.Notifications {
+		response.Items = append(response.Items, notificationResponse{
+			ID:            n.ID,
+			Kind:          n.Kind,
+			ActorID:       n.ActorID,
+			ActorUsername: n.ActorUsername,
+			TargetID:      n.TargetID,
+			ReadAt:        n.ReadAt,
+			CreatedAt:     n.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+type notificationMarkReadRequest struct {
+	IDs []uuid.UUID `json:"ids" binding:"max=100"`
+}
+
+// @Summary Прочтение уведомлений
+// @Tags Notifications
+// @Description Отмечает уведомления прочитанными. Если список ids пуст, прочитанными отмечаются все уведомления
+// @ModuleID Notifications
+// @Accept  json
+// @Produce  json
+// @Param input body notificationMarkReadRequest true "Уведомления"
+// @Success 204
+// @Failure 400 {object} ErrorStruct
+// @Router /users/me/notifications/read [post]
+// @Security Bearer
+func (h *Handler) notificationMarkRead(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		h.logger.Error("failed to get user id", "error", err)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	var req notificationMarkReadRequest
+	if err := c.BindJSON(&req); err != nil {
+		validationErrorResponse(c, err)
+		return
+	}
+
+	if err := h.services.Notifications.MarkRead(c.Request.Context(), userID, req.IDs); err != nil {
+		h.logger.Error("failed to mark notifications read",
+			"error", err,
+		)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
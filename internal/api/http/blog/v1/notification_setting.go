@@ -0,0 +1,190 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/newnorthblog/backend/internal/domain"
+	"github.com/newnorthblog/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+func (h *Handler) initNotificationSettingRoutes(api *gin.RouterGroup) {
+	settings := api.Group("/users/me/notification-settings", h.userIdentityMiddleware)
+	settings.GET("", h.notificationSettingsGet)
+	settings.PUT("", h.notificationSettingsUpdate)
+
+	// unsubscribe links from emails, authenticated by the token: GET only asks
+	// to confirm, POST unsubscribes and also serves one-click requests (RFC 8058)
+	api.GET("/notification-settings/unsubscribe", h.notificationUnsubscribeConfirm)
+	api.POST("/notification-settings/unsubscribe", h.notificationUnsubscribe)
+}
+
+type notificationSettingResponse struct {
+	Kind      string `json:"kind"`
+	Frequency string `json:"frequency"`
+}
+
+type notificationSettingsResponse struct {
+	Settings []notificationSettingResponse `json:"settings"`
+}
+
+// @Summary Настройки email уведомлений
+// @Tags Notifications
+// @Description Частота писем для каждого типа уведомлений: immediate, daily, weekly или off
+// @ModuleID Notifications
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} notificationSettingsResponse
+// @Failure 400 {object} ErrorStruct
+// @Router /users/me/notification-settings [get]
+// @Security Bearer
+func (h *Handler) notificationSettingsGet(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		h.logger.Error("failed to get user id", "error", err)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	settings, err := h.services.NotificationSettings.Get(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("failed to get notification settings",
+			"error", err,
+		)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	response := notificationSettingsResponse{
+		Settings: make([]notificationSettingResponse, 0, len(settings)),
+	}
+	for _, s := range settings {
+		response.Settings = append(response.Settings, notificationSettingResponse{
+			Kind:      s.Kind,
+			Frequency: s.Frequency,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+type notificationSettingRequest struct {
+	Kind      string `json:"kind" binding:"required"`
+	Frequency string `json:"frequency" binding:"required"`
+}
+
+type notificationSettingsUpdateRequest struct {
+	Settings []notificationSettingRequest `json:"settings" binding:"required,dive"`
+}
+
+// @Summary Изменение настроек email уведомлений
+// @Tags Notifications
+// @Description Изменение частоты писем для типов уведомлений: immediate, daily, weekly или off
+// @ModuleID Notifications
+// @Accept  json
+// @Produce  json
+// @Param input body notificationSettingsUpdateRequest true "Настройки"
+// @Success 204
+// @Failure 400 {object} ErrorStruct
+// @Router /users/me/notification-settings [put]
+// @Security Bearer
+func (h *Handler) notificationSettingsUpdate(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		h.logger.Error("failed to get user id", "error", err)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	var req notificationSettingsUpdateRequest
+	if err := c.BindJSON(&req); err != nil {
+		validationErrorResponse(c, err)
+		return
+	}
+
+	settings := make([]domain.NotificationSetting, 0, len(req.Settings))
+	for _, s := range req.Settings {
+		settings = append(settings, domain.NotificationSetting{
+			Kind:      s.Kind,
+			Frequency: s.Frequency,
+		})
+	}
+
+	if err := h.services.NotificationSettings.Update(c.Request.Context(), userID, settings); err != nil {
+		if errors.Is(err, service.ErrUnknownNotificationKind) {
+			errorResponse(c, NotificationUnknownKindCode)
+			return
+		}
+		if errors.Is(err, service.ErrUnknownNotificationFrequency) {
+			errorResponse(c, NotificationUnknownFrequencyCode)
+			return
+		}
+		h.logger.Error("failed to update notification settings",
+			"error", err,
+		)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+type notificationUnsubscribeRequest struct {
+	Token string `form:"token" binding:"required"`
+}
+
+// @Summary Подтверждение отписки от email уведомлений
+// @Tags Notifications
+// @Description Страница по ссылке из письма с формой подтверждения отписки, сама ничего не меняет
+// @ModuleID Notifications
+// @Produce  html
+// @Param token query string true "Токен отписки"
+// @Success 200
+// @Failure 400 {object} ErrorStruct
+// @Router /notification-settings/unsubscribe [get]
+func (h *Handler) notificationUnsubscribeConfirm(c *gin.Context) {
+	var req notificationUnsubscribeRequest
+	if err := c.BindQuery(&req); err != nil {
+		validationErrorResponse(c, err)
+		return
+	}
+
+	h.unsubscribeConfirm(c, "Отписка от уведомлений", "Отключить все письма с уведомлениями New North?", req.Token)
+}
+
+// @Summary Отписка от email уведомлений
+// @Tags Notifications
+// @Description Отключает все письма с уведомлениями. Токен передается в форме или, для отписки в один клик (RFC 8058), в строке запроса
+// @ModuleID Notifications
+// @Accept  x-www-form-urlencoded
+// @Produce  json
+// @Param token formData string true "Токен отписки"
+// @Success 204
+// @Failure 400 {object} ErrorStruct
+// @Router /notification-settings/unsubscribe [post]
+func (h *Handler) notificationUnsubscribe(c *gin.Context) {
+	// the form binding reads the body and the query, one-click clients post
+	// to the link from List-Unsubscribe with the token in the query
+	var req notificationUnsubscribeRequest
+	if err := c.MustBindWith(&req, binding.Form); err != nil {
+		validationErrorResponse(c, err)
+		return
+	}
+
+	if err := h.services.NotificationSettings.Unsubscribe(c.Request.Context(), req.Token); err != nil {
+		if errors.Is(err, service.ErrInvalidUnsubscribeToken) {
+			errorResponse(c, NotificationInvalidUnsubscribeCode)
+			return
+		}
+		h.logger.Error("failed to unsubscribe from notifications",
+			"error", err,
+		)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
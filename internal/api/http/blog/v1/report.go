@@ -0,0 +1,103 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/newnorthblog/backend/internal/domain"
+	"github.com/newnorthblog/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) initReportRoutes(api *gin.RouterGroup) {
+	reports := api.Group("/reports", h.userIdentityMiddleware)
+	reports.POST("", h.reportCreate)
+}
+
+type reportCreateRequest struct {
+	TargetType string    `json:"target_type" binding:"required"`
+	TargetID   uuid.UUID `json:"target_id" binding:"required"`
+	Reason     string    `json:"reason" binding:"required,min=3,max=1000"`
+}
+
+type reportResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	ReporterID uuid.UUID  `json:"reporter_id"`
+	TargetType string     `json:"target_type"`
+	TargetID   uuid.UUID  `json:"target_id"`
+	Reason     string     `json:"reason"`
+	Status     string     `json:"status"`
+	ResolvedBy *uuid.UUID `json:"resolved_by"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func newReportResponse(r *domain.Report) reportResponse {
+	return reportResponse{
+		ID:         r.ID,
+		ReporterID: r.ReporterID,
+		TargetType: r.TargetType,
+		TargetID:   r.TargetID,
+		Reason:     r.Reason,
+		Status:     r.Status,
+		ResolvedBy: r.ResolvedBy,
+		ResolvedAt: r.ResolvedAt,
+		CreatedAt:  r.CreatedAt,
+	}
+}
+
+// @Summary Жалоба
+// @Tags Reports
+// @Description Жалоба на пользователя. Поддерживаемые target_type: user
+// @ModuleID Reports
+// @Accept  json
+// @Produce  json
+// @Param input body reportCreateRequest true "Жалоба"
+// @Success 201 {object} reportResponse
+// @Failure 400 {object} ErrorStruct
+// @Router /reports [post]
+// @Security Bearer
+func (h *Handler) reportCreate(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		h.logger.Error("failed to get user id", "error", err)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	var req reportCreateRequest
+	if err := c.BindJSON(&req); err != nil {
+		validationErrorResponse(c, err)
+		return
+	}
+
+	report, err := h.services.Moderation.Report(c.Request.Context(), &service.ReportInput{
+		ReporterID: userID,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		Reason:     req.Reason,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrReportUnsupportedTarget):
+			errorResponse(c, ReportUnsupportedTargetCode)
+		case errors.Is(err, service.ErrReportSelf):
+			errorResponse(c, ReportSelfCode)
+		case errors.Is(err, service.ErrReportAlreadyExists):
+			errorResponse(c, ReportAlreadyExistsCode)
+		case errors.Is(err, service.ErrUserNotFound):
+			errorResponse(c, UserNotFoundCode)
+		default:
+			h.logger.Error("failed to create report",
+				"error", err,
+			)
+			c.Status(http.StatusBadRequest)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, newReportResponse(report))
+}
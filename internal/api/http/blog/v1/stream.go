@@ -0,0 +1,110 @@
+package v1
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/newnorthblog/backend/internal/service"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const lastEventIDHeader = "Last-Event-ID"
+
+func (h *Handler) initStreamRoutes(api *gin.RouterGroup) {
+	api.GET("/stream", h.userIdentityMiddleware, h.stream)
+}
+
+// @Summary Поток событий
+// @Tags Stream
+// @Description Server-Sent Events: уведомления (notification) и heartbeat (ping). Для возобновления передайте заголовок Last-Event-ID
+// @ModuleID Stream
+// @Produce  text/event-stream
+// @Param Last-Event-ID header string false "Идентификатор последнего полученного события"
+// @Success 200 {object} notificationResponse
+// @Failure 400 {object} ErrorStruct
+// @Router /stream [get]
+// @Security Bearer
+func (h *Handler) stream(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		h.logger.Error("failed to get user id", "error", err)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	sub, err := h.services.Stream.Subscribe(c.Request.Context(), userID, c.GetHeader(lastEventIDHeader))
+	if err != nil {
+		h.logger.Error("failed to subscribe to stream",
+			"error", err,
+		)
+		c.Status(http.StatusServiceUnavailable)
+		return
+	}
+	defer sub.Close()
+
+	// the stream outlives the server write timeout
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		h.logger.Error("failed to reset write deadline", "error", err)
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	sent := make(map[uuid.UUID]struct{}, len(sub.Backlog))
+	for _, event := range sub.Backlog {
+		renderStreamEvent(c, event)
+		sent[event.Notification.ID] = struct{}{}
+	}
+	// c.Stream flushes only after a step, and the first one waits for a live
+	// event, so the headers and the backlog have to go out now
+	c.Writer.Flush()
+
+	c.Stream(func(_ io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-sub.Events:
+			if !ok {
+				return false
+			}
+			if event.Notification != nil {
+				if _, ok := sent[event.Notification.ID]; ok {
+					return true
+				}
+			}
+			renderStreamEvent(c, event)
+			return true
+		}
+	})
+}
+
+func renderStreamEvent(c *gin.Context, event service.StreamEvent) {
+	switch event.Kind {
+	case service.StreamEventNotification:
+		n := event.Notification
+		c.Render(-1, sse.Event{
+			Id:    n.ID.String(),
+			Event: event.Kind,
+			Data: notificationResponse{
+				ID:            n.ID,
+				Kind:          n.Kind,
+				ActorID:       n.ActorID,
+				ActorUsername: n.ActorUsername,
+				TargetID:      n.TargetID,
+				ReadAt:        n.ReadAt,
+				CreatedAt:     n.CreatedAt,
+			},
+		})
+	case service.StreamEventPing:
+		c.Render(-1, sse.Event{
+			Event: event.Kind,
+			Data:  time.Now().Unix(),
+		})
+	}
+}
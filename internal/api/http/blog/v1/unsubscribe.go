@@ -0,0 +1,40 @@
+package v1
+
+import (
+	"embed"
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed templates/unsubscribe.html.tmpl
+var unsubscribeTemplates embed.FS
+
+var unsubscribeConfirmHTML = template.Must(template.ParseFS(unsubscribeTemplates, "templates/unsubscribe.html.tmpl"))
+
+type unsubscribeConfirmData struct {
+	Title  string
+	Text   string
+	Action string
+	Token  string
+}
+
+// unsubscribeConfirm renders a page that asks to confirm the unsubscription
+// with a form posted back to the same path. Links from emails are opened by
+// mail scanners too, so a GET must not change anything.
+func (h *Handler) unsubscribeConfirm(c *gin.Context, title, text, token string) {
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	if err := unsubscribeConfirmHTML.Execute(c.Writer, &unsubscribeConfirmData{
+		Title:  title,
+		Text:   text,
+		Action: c.Request.URL.Path,
+		Token:  token,
+	}); err != nil {
+		h.logger.Error("failed to render unsubscribe page",
+			"error", err,
+		)
+	}
+}
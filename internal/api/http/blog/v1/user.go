@@ -13,6 +13,7 @@ func (h *Handler) initUserRoutes(api *gin.RouterGroup) {
 	users := api.Group("/users")
 	users.POST("/register", h.userRegister)
 	users.POST("/login", h.userAuth)
+	users.POST("/password/reset", h.userPasswordReset)
 	users.POST("ping", h.userIdentityMiddleware, h.ping)
 }
 
@@ -95,6 +96,18 @@ func (h *Handler) userAuth(c *gin.Context) {
 			errorResponse(c, UserNotFoundCode)
 			return
 		}
+		if errors.Is(err, service.ErrUserSuspended) {
+			errorResponse(c, UserSuspendedCode)
+			return
+		}
+		if errors.Is(err, service.ErrUserBanned) {
+			errorResponse(c, UserBannedCode)
+			return
+		}
+		if errors.Is(err, service.ErrUserPasswordResetRequired) {
+			errorResponse(c, UserPasswordResetRequiredCode)
+			return
+		}
 
 		h.logger.Error("failed to login client",
 			"error", err,
@@ -106,6 +119,43 @@ func (h *Handler) userAuth(c *gin.Context) {
 	c.JSON(http.StatusOK, userLoginResponse{AccessToken: token.AccessToken})
 }
 
+type userPasswordResetRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+// @Summary Сброс пароля
+// @Tags Client
+// @Description Установка нового пароля по коду из письма
+// @ModuleID Client
+// @Accept  json
+// @Produce  json
+// @Param input body userPasswordResetRequest true "Сброс пароля"
+// @Success 204
+// @Failure 400 {object} ErrorStruct
+// @Router /users/password/reset [post]
+func (h *Handler) userPasswordReset(c *gin.Context) {
+	var req userPasswordResetRequest
+	if err := c.BindJSON(&req); err != nil {
+		validationErrorResponse(c, err)
+		return
+	}
+
+	if err := h.services.Users.ResetPassword(c.Request.Context(), req.Token, req.Password); err != nil {
+		if errors.Is(err, service.ErrInvalidPasswordResetToken) {
+			errorResponse(c, PasswordResetTokenInvalidCode)
+			return
+		}
+		h.logger.Error("failed to reset password",
+			"error", err,
+		)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
 // @Summary Ping
 // @Tags Client
 // @Description Проверка доступности сервера
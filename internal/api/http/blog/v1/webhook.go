@@ -0,0 +1,433 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/newnorthblog/backend/internal/domain"
+	"github.com/newnorthblog/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type webhookEndpointRequest struct {
+	URL    string   `json:"url" binding:"required,url,max=2048"`
+	Events []string `json:"events" binding:"required,min=1,dive,required"`
+	Active *bool    `json:"active"`
+}
+
+func (r *webhookEndpointRequest) input() *service.WebhookEndpointInput {
+	// endpoints are active unless created disabled
+	active := true
+	if r.Active != nil {
+		active = *r.Active
+	}
+
+	return &service.WebhookEndpointInput{
+		URL:    r.URL,
+		Events: r.Events,
+		Active: active,
+	}
+}
+
+type webhookEndpointResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	URL       string     `json:"url"`
+	Events    []string   `json:"events"`
+	Active    bool       `json:"active"`
+	CreatedBy *uuid.UUID `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func newWebhookEndpointResponse(e *domain.WebhookEndpoint) webhookEndpointResponse {
+	return webhookEndpointResponse{
+		ID:        e.ID,
+		URL:       e.URL,
+		Events:    e.Events,
+		Active:    e.Active,
+		CreatedBy: e.CreatedBy,
+		CreatedAt: e.CreatedAt,
+		UpdatedAt: e.UpdatedAt,
+	}
+}
+
+type webhookEndpointCreateResponse struct {
+	webhookEndpointResponse
+	Secret string `json:"secret"`
+}
+
+type webhookEndpointListResponse struct {
+	Items []webhookEndpointResponse `json:"items"`
+}
+
+type webhookDeliveryResponse struct {
+	ID             uuid.UUID       `json:"id"`
+	EndpointID     uuid.UUID       `json:"endpoint_id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at"`
+	ResponseStatus *int            `json:"response_status"`
+	LastError      *string         `json:"last_error"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+func newWebhookDeliveryResponse(d *domain.WebhookDelivery) webhookDeliveryResponse {
+	return webhookDeliveryResponse{
+		ID:             d.ID,
+		EndpointID:     d.EndpointID,
+		Event:          d.Event,
+		Payload:        d.Payload,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttemptAt,
+		LastAttemptAt:  d.LastAttemptAt,
+		ResponseStatus: d.ResponseStatus,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt,
+	}
+}
+
+type webhookDeliveryListResponse struct {
+	Items      []webhookDeliveryResponse `json:"items"`
+	NextCursor string                    `json:"next_cursor"`
+}
+
+type webhookDeliveryAttemptResponse struct {
+	ID             uuid.UUID `json:"id"`
+	ResponseStatus *int      `json:"response_status"`
+	Error          *string   `json:"error"`
+	DurationMS     int       `json:"duration_ms"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type webhookDeliveryDetailResponse struct {
+	webhookDeliveryResponse
+	AttemptLog []webhookDeliveryAttemptResponse `json:"attempt_log"`
+}
+
+// @Summary Вебхуки
+// @Tags Admin
+// @Description Список исходящих вебхуков
+// @ModuleID Admin
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} webhookEndpointListResponse
+// @Failure 400 {object} ErrorStruct
+// @Failure 403 {object} ErrorStruct
+// @Router /admin/webhooks [get]
+// @Security Bearer
+func (h *Handler) adminWebhookList(c *gin.Context) {
+	endpoints, err := h.services.Webhooks.ListEndpoints(c.Request.Context())
+	if err != nil {
+		h.logger.Error("failed to list webhooks",
+			"error", err,
+		)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	response := webhookEndpointListResponse{
+		Items: make([]webhookEndpointResponse, 0, len(endpoints)),
+	}
+	for i := range endpoints {
+		response.Items = append(response.Items, newWebhookEndpointResponse(&endpoints[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Новый вебхук
+// @Tags Admin
+// @Description Создает вебхук на выбранные события. Секрет для проверки подписи X-Webhook-Signature возвращается только в этом ответе
+// @ModuleID Admin
+// @Accept  json
+// @Produce  json
+// @Param input body webhookEndpointRequest true "Вебхук"
+// @Success 201 {object} webhookEndpointCreateResponse
+// @Failure 400 {object} ErrorStruct
+// @Failure 403 {object} ErrorStruct
+// @Router /admin/webhooks [post]
+// @Security Bearer
+func (h *Handler) adminWebhookCreate(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		h.logger.Error("failed to get user id", "error", err)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	var req webhookEndpointRequest
+	if err := c.BindJSON(&req); err != nil {
+		validationErrorResponse(c, err)
+		return
+	}
+
+	endpoint, err := h.services.Webhooks.CreateEndpoint(c.Request.Context(), adminID, req.input())
+	if err != nil {
+		if errors.Is(err, service.ErrUnknownWebhookEvent) {
+			errorResponse(c, WebhookUnknownEventCode)
+			return
+		}
+		if errors.Is(err, service.ErrInvalidWebhookURL) {
+			errorResponse(c, WebhookInvalidURLCode)
+			return
+		}
+		h.logger.Error("failed to create webhook",
+			"error", err,
+		)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhookEndpointCreateResponse{
+		webhookEndpointResponse: newWebhookEndpointResponse(endpoint),
+		Secret:                  endpoint.Secret,
+	})
+}
+
+// @Summary Изменение вебхука
+// @Tags Admin
+// @Description Меняет адрес, события и активность вебхука. Секрет не меняется
+// @ModuleID Admin
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Идентификатор вебхука"
+// @Param input body webhookEndpointRequest true "Вебхук"
+// @Success 200 {object} webhookEndpointResponse
+// @Failure 400 {object} ErrorStruct
+// @Failure 403 {object} ErrorStruct
+// @Router /admin/webhooks/{id} [put]
+// @Security Bearer
+func (h *Handler) adminWebhookUpdate(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		h.logger.Error("failed to get user id", "error", err)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	endpointID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		errorResponse(c, WebhookNotFoundCode)
+		return
+	}
+
+	var req webhookEndpointRequest
+	if err := c.BindJSON(&req); err != nil {
+		validationErrorResponse(c, err)
+		return
+	}
+
+	endpoint, err := h.services.Webhooks.UpdateEndpoint(c.Request.Context(), adminID, endpointID, req.input())
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnknownWebhookEvent):
+			errorResponse(c, WebhookUnknownEventCode)
+		case errors.Is(err, service.ErrInvalidWebhookURL):
+			errorResponse(c, WebhookInvalidURLCode)
+		case errors.Is(err, service.ErrWebhookNotFound):
+			errorResponse(c, WebhookNotFoundCode)
+		default:
+			h.logger.Error("failed to update webhook",
+				"error", err,
+			)
+			c.Status(http.StatusBadRequest)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, newWebhookEndpointResponse(endpoint))
+}
+
+// @Summary Удаление вебхука
+// @Tags Admin
+// @Description Удаляет вебхук вместе с его доставками
+// @ModuleID Admin
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Идентификатор вебхука"
+// @Success 204
+// @Failure 400 {object} ErrorStruct
+// @Failure 403 {object} ErrorStruct
+// @Router /admin/webhooks/{id} [delete]
+// @Security Bearer
+func (h *Handler) adminWebhookDelete(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		h.logger.Error("failed to get user id", "error", err)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	endpointID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		errorResponse(c, WebhookNotFoundCode)
+		return
+	}
+
+	if err := h.services.Webhooks.DeleteEndpoint(c.Request.Context(), adminID, endpointID); err != nil {
+		if errors.Is(err, service.ErrWebhookNotFound) {
+			errorResponse(c, WebhookNotFoundCode)
+			return
+		}
+		h.logger.Error("failed to delete webhook",
+			"error", err,
+		)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Доставки вебхука
+// @Tags Admin
+// @Description Список доставок вебхука, новые первыми
+// @ModuleID Admin
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Идентификатор вебхука"
+// @Param cursor query string false "Курсор следующей страницы"
+// @Param limit query int false "Размер страницы"
+// @Success 200 {object} webhookDeliveryListResponse
+// @Failure 400 {object} ErrorStruct
+// @Failure 403 {object} ErrorStruct
+// @Router /admin/webhooks/{id}/deliveries [get]
+// @Security Bearer
+func (h *Handler) adminWebhookDeliveryList(c *gin.Context) {
+	endpointID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		errorResponse(c, WebhookNotFoundCode)
+		return
+	}
+
+	var req cursorRequest
+	if err := c.BindQuery(&req); err != nil {
+		validationErrorResponse(c, err)
+		return
+	}
+
+	list, err := h.services.Webhooks.ListDeliveries(c.Request.Context(), endpointID, req.Cursor, req.Limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCursor):
+			errorResponse(c, InvalidCursorCode)
+		case errors.Is(err, service.ErrWebhookNotFound):
+			errorResponse(c, WebhookNotFoundCode)
+		default:
+			h.logger.Error("failed to list webhook deliveries",
+				"error", err,
+			)
+			c.Status(http.StatusBadRequest)
+		}
+		return
+	}
+
+	response := webhookDeliveryListResponse{
+		Items:      make([]webhookDeliveryResponse, 0, len(list.Deliveries)),
+		NextCursor: list.NextCursor,
+	}
+	for i := range list.Deliveries {
+		response.Items = append(response.Items, newWebhookDeliveryResponse(&list.Deliveries[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Доставка вебхука
+// @Tags Admin
+// @Description Доставка с журналом всех попыток
+// @ModuleID Admin
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Идентификатор доставки"
+// @Success 200 {object} webhookDeliveryDetailResponse
+// @Failure 400 {object} ErrorStruct
+// @Failure 403 {object} ErrorStruct
+// @Router /admin/webhook-deliveries/{id} [get]
+// @Security Bearer
+func (h *Handler) adminWebhookDeliveryGet(c *gin.Context) {
+	deliveryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		errorResponse(c, WebhookDeliveryNotFoundCode)
+		return
+	}
+
+	delivery, attempts, err := h.services.Webhooks.GetDelivery(c.Request.Context(), deliveryID)
+	if err != nil {
+		if errors.Is(err, service.ErrWebhookDeliveryNotFound) {
+			errorResponse(c, WebhookDeliveryNotFoundCode)
+			return
+		}
+		h.logger.Error("failed to get webhook delivery",
+			"error", err,
+		)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	response := webhookDeliveryDetailResponse{
+		webhookDeliveryResponse: newWebhookDeliveryResponse(delivery),
+		AttemptLog:              make([]webhookDeliveryAttemptResponse, 0, len(attempts)),
+	}
+	for _, a := range attempts {
+		response.AttemptLog = append(response.AttemptLog, webhookDeliveryAttemptResponse{
+			ID:             a.ID,
+			ResponseStatus: a.ResponseStatus,
+			Error:          a.Error,
+			DurationMS:     a.DurationMS,
+			CreatedAt:      a.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Повторная доставка вебхука
+// @Tags Admin
+// @Description Ставит доставку в очередь заново с полным числом попыток
+// @ModuleID Admin
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Идентификатор доставки"
+// @Success 202 {object} webhookDeliveryResponse
+// @Failure 400 {object} ErrorStruct
+// @Failure 403 {object} ErrorStruct
+// @Router /admin/webhook-deliveries/{id}/redeliver [post]
+// @Security Bearer
+func (h *Handler) adminWebhookRedeliver(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		h.logger.Error("failed to get user id", "error", err)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	deliveryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		errorResponse(c, WebhookDeliveryNotFoundCode)
+		return
+	}
+
+	delivery, err := h.services.Webhooks.Redeliver(c.Request.Context(), adminID, deliveryID)
+	if err != nil {
+		if errors.Is(err, service.ErrWebhookDeliveryNotFound) {
+			errorResponse(c, WebhookDeliveryNotFoundCode)
+			return
+		}
+		h.logger.Error("failed to redeliver webhook",
+			"error", err,
+		)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, newWebhookDeliveryResponse(delivery))
+}
@@ -27,17 +27,20 @@ type Handler struct {
 	services     *service.Services
 	logger       *slog.Logger
 	tokenManager *tokenmanager.Manager
+	limiterStore limiter.Store
 }
 
 func NewHandlers(
 	services *service.Services,
 	logger *slog.Logger,
 	tokenManager *tokenmanager.Manager,
+	limiterStore limiter.Store,
 ) *Handler {
 	return &Handler{
 		services:     services,
 		logger:       logger,
 		tokenManager: tokenManager,
+		limiterStore: limiterStore,
 	}
 }
 
@@ -45,6 +48,13 @@ func (h *Handler) Init(cfg *config.Config) *gin.Engine {
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.New()
 
+	// the client address goes to the audit log, so X-Forwarded-For is taken
+	// only from the configured proxies
+	if err := router.SetTrustedProxies(cfg.HTTPServer.TrustedProxies); err != nil {
+		h.logger.Error("invalid trusted proxies, trusting none", "error", err)
+		router.SetTrustedProxies(nil)
+	}
+
 	validator.RegisterGinValidator()
 
 	router.Use(
@@ -52,7 +62,7 @@ func (h *Handler) Init(cfg *config.Config) *gin.Engine {
 			WithSpanID:  true,
 			WithTraceID: true,
 		}),
-		limiter.Limit(cfg.Limiter.RPS, cfg.Limiter.Burst, cfg.Limiter.TTL, h.logger),
+		limiter.Limit(h.limiterStore, h.logger),
 		corsMiddleware,
 	)
 
@@ -68,6 +78,10 @@ func (h *Handler) Init(cfg *config.Config) *gin.Engine {
 		router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.NewHandler(), ginSwagger.InstanceName("blogV1")))
 	}
 
+	if cfg.Storage.Backend == "local" {
+		h.initLocalMedia(router, cfg.Storage)
+	}
+
 	h.initAPI(router)
 
 	return router
@@ -80,3 +94,12 @@ func (h *Handler) initAPI(router *gin.Engine) {
 		appHandlersV1.Init(api)
 	}
 }
+
+// initLocalMedia serves files of the local storage backend.
+func (h *Handler) initLocalMedia(router *gin.Engine, cfg config.Storage) {
+	media := router.Group(cfg.LocalURL, func(c *gin.Context) {
+		// let the file server detect the type instead of the JSON default set by corsMiddleware
+		c.Writer.Header().Del("Content-Type")
+	})
+	media.Static("/", cfg.LocalDir)
+}
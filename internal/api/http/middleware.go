@@ -10,6 +10,7 @@ func corsMiddleware(c *gin.Context) {
 	c.Header("Access-Control-Allow-Origin", "*")
 	c.Header("Access-Control-Allow-Methods", "*")
 	c.Header("Access-Control-Allow-Headers", "*")
+	c.Header("Access-Control-Expose-Headers", "X-Impersonated-By")
 	c.Header("Content-Type", "application/json")
 
 	if c.Request.Method != "OPTIONS" {
@@ -10,11 +10,21 @@ import (
 )
 
 type Config struct {
-	Env        string `env:"ENV" env-required:"true" comment:"Среда выполнения приложения"`
-	HTTPServer HTTPServer
-	Database   Database
-	Limiter    Limiter
-	JWT        JWT
+	Env           string `env:"ENV" env-required:"true" comment:"Среда выполнения приложения"`
+	HTTPServer    HTTPServer
+	Database      Database
+	Limiter       Limiter
+	JWT           JWT
+	PasswordReset PasswordReset
+	Admin         Admin
+	Media         Media
+	Storage       Storage
+	Stream        Stream
+	Mailer        Mailer
+	Digest        Digest
+	Newsletter    Newsletter
+	Webhook       Webhook
+	Outbox        Outbox
 }
 
 type HTTPServer struct {
@@ -22,6 +32,7 @@ type HTTPServer struct {
 	Timeout        time.Duration `env:"HTTP_SERVER_TIMEOUT" env-default:"4s" comment:"Таймаут для HTTP сервера"`
 	IdleTimeout    time.Duration `env:"HTTP_SERVER_IDLE_TIMEOUT" env-default:"60s" comment:"Таймаут бездействия для HTTP сервера"`
 	SwaggerEnabled bool          `env:"HTTP_SERVER_SWAGGER_ENABLED" comment:"Включить Swagger"`
+	TrustedProxies []string      `env:"HTTP_SERVER_TRUSTED_PROXIES" env-separator:"," comment:"Адреса и подсети прокси, которым доверяется X-Forwarded-For, по умолчанию никому"`
 }
 
 type Database struct {
@@ -39,15 +50,96 @@ type Database struct {
 }
 
 type Limiter struct {
-	RPS   int           `env:"LIMITER_RPS" env-default:"10" comment:"Запросов в секунду"`
-	Burst int           `env:"LIMITER_BURST" env-default:"20" comment:"Максимальное количество запросов в секунду"`
-	TTL   time.Duration `env:"LIMITER_TTL" env-default:"10m" comment:"Время жизни лимита"`
+	RPS      int           `env:"LIMITER_RPS" env-default:"10" comment:"Запросов в секунду"`
+	Burst    int           `env:"LIMITER_BURST" env-default:"20" comment:"Максимальное количество запросов в секунду"`
+	TTL      time.Duration `env:"LIMITER_TTL" env-default:"10m" comment:"Время жизни лимита"`
+	Store    string        `env:"LIMITER_STORE" env-default:"memory" comment:"Хранилище лимитов: memory или redis"`
+	RedisURL string        `env:"LIMITER_REDIS_URL" env-default:"redis://localhost:6379/0" comment:"Адрес Redis для хранилища лимитов"`
 }
 
 type JWT struct {
-	SecretKey       string        `env:"JWT_SECRET_KEY" env-default:"notasecret" comment:"Секретный ключ для JWT"`
-	AccessTokenTTL  time.Duration `env:"JWT_ACCESS_TOKEN_TTL" env-default:"15m" comment:"Время жизни access токена"`
-	RefreshTokenTTL time.Duration `env:"JWT_REFRESH_TOKEN_TTL" env-default:"720h" comment:"Время жизни refresh токена"`
+	SecretKey        string        `env:"JWT_SECRET_KEY" env-default:"notasecret" comment:"Секретный ключ для JWT"`
+	AccessTokenTTL   time.Duration `env:"JWT_ACCESS_TOKEN_TTL" env-default:"15m" comment:"Время жизни access токена"`
+	RefreshTokenTTL  time.Duration `env:"JWT_REFRESH_TOKEN_TTL" env-default:"720h" comment:"Время жизни refresh токена"`
+	ImpersonationTTL time.Duration `env:"JWT_IMPERSONATION_TTL" env-default:"10m" comment:"Время жизни токена для входа администратора под пользователем"`
+}
+
+type PasswordReset struct {
+	TTL time.Duration `env:"PASSWORD_RESET_TTL" env-default:"24h" comment:"Время жизни кода сброса пароля"`
+}
+
+type Admin struct {
+	Email string `env:"ADMIN_EMAIL" comment:"Почта зарегистрированного пользователя, который получает роль администратора при запуске"`
+}
+
+type Media struct {
+	MaxSize      int64    `env:"MEDIA_MAX_SIZE" env-default:"10485760" comment:"Максимальный размер загружаемого файла в байтах"`
+	AllowedTypes []string `env:"MEDIA_ALLOWED_TYPES" env-default:"image/jpeg,image/png,image/gif,image/webp" env-separator:"," comment:"Разрешенные MIME типы загружаемых файлов"`
+	Widths       []int    `env:"MEDIA_VARIANT_WIDTHS" env-default:"320,768,1280" env-separator:"," comment:"Ширины адаптивных вариантов изображений"`
+	JPEGQuality  int      `env:"MEDIA_JPEG_QUALITY" env-default:"85" comment:"Качество JPEG при обработке изображений"`
+	Workers      int      `env:"MEDIA_WORKERS" env-default:"4" comment:"Количество обработчиков изображений"`
+	QueueSize    int      `env:"MEDIA_QUEUE_SIZE" env-default:"100" comment:"Размер очереди обработки изображений"`
+}
+
+type Storage struct {
+	Backend     string `env:"STORAGE_BACKEND" env-default:"local" comment:"Хранилище файлов: local или s3"`
+	LocalDir    string `env:"STORAGE_LOCAL_DIR" env-default:"./.data/media" comment:"Директория для локального хранилища"`
+	LocalURL    string `env:"STORAGE_LOCAL_URL" env-default:"/media" comment:"URL префикс для файлов локального хранилища"`
+	S3Endpoint  string `env:"STORAGE_S3_ENDPOINT" comment:"Адрес S3 совместимого хранилища"`
+	S3Region    string `env:"STORAGE_S3_REGION" env-default:"us-east-1" comment:"Регион S3 хранилища"`
+	S3Bucket    string `env:"STORAGE_S3_BUCKET" comment:"Бакет S3 хранилища"`
+	S3AccessKey string `env:"STORAGE_S3_ACCESS_KEY" comment:"Ключ доступа S3 хранилища"`
+	S3SecretKey string `env:"STORAGE_S3_SECRET_KEY" comment:"Секретный ключ S3 хранилища"`
+	S3PublicURL string `env:"STORAGE_S3_PUBLIC_URL" comment:"Публичный URL для файлов S3 хранилища"`
+}
+
+type Stream struct {
+	HeartbeatInterval time.Duration `env:"STREAM_HEARTBEAT_INTERVAL" env-default:"15s" comment:"Интервал heartbeat сообщений SSE"`
+	ReplayLimit       int           `env:"STREAM_REPLAY_LIMIT" env-default:"100" comment:"Максимальное количество событий, отправляемых при возобновлении SSE"`
+}
+
+type Mailer struct {
+	Backend           string `env:"MAILER_BACKEND" env-default:"file" comment:"Способ отправки писем: smtp или file"`
+	From              string `env:"MAILER_FROM" env-default:"New North <noreply@localhost>" comment:"Адрес отправителя писем"`
+	SMTPHost          string `env:"MAILER_SMTP_HOST" comment:"Хост SMTP сервера"`
+	SMTPPort          string `env:"MAILER_SMTP_PORT" env-default:"587" comment:"Порт SMTP сервера"`
+	SMTPUser          string `env:"MAILER_SMTP_USER" comment:"Пользователь SMTP сервера"`
+	SMTPPassword      string `env:"MAILER_SMTP_PASSWORD" comment:"Пароль пользователя SMTP сервера"`
+	FileDir           string `env:"MAILER_FILE_DIR" env-default:"./.data/mail" comment:"Директория для писем при отправке в файлы"`
+	BaseURL           string `env:"MAILER_BASE_URL" env-default:"http://localhost:8080" comment:"Публичный адрес API для ссылок в письмах"`
+	UnsubscribeSecret string `env:"MAILER_UNSUBSCRIBE_SECRET" env-default:"notasecret" comment:"Секретный ключ для ссылок отписки"`
+}
+
+type Digest struct {
+	Interval         time.Duration `env:"DIGEST_INTERVAL" env-default:"1m" comment:"Интервал проверки писем с уведомлениями"`
+	BatchSize        int           `env:"DIGEST_BATCH_SIZE" env-default:"100" comment:"Количество получателей писем с уведомлениями, обрабатываемых за одну проверку"`
+	Lease            time.Duration `env:"DIGEST_LEASE" env-default:"5m" comment:"Время, на которое проверка забирает уведомления для отправки"`
+	DefaultFrequency string        `env:"DIGEST_DEFAULT_FREQUENCY" env-default:"daily" comment:"Частота писем по умолчанию: immediate, daily, weekly или off"`
+}
+
+type Newsletter struct {
+	Interval      time.Duration `env:"NEWSLETTER_INTERVAL" env-default:"30s" comment:"Интервал отправки очередной партии писем рассылки"`
+	BatchSize     int           `env:"NEWSLETTER_BATCH_SIZE" env-default:"100" comment:"Количество писем рассылки в одной партии"`
+	Lease         time.Duration `env:"NEWSLETTER_LEASE" env-default:"5m" comment:"Время, на которое партия забирает подписчиков для отправки"`
+	ConfirmTTL    time.Duration `env:"NEWSLETTER_CONFIRM_TTL" env-default:"48h" comment:"Время жизни ссылки подтверждения подписки"`
+	WebhookSecret string        `env:"NEWSLETTER_WEBHOOK_SECRET" comment:"Секретный ключ вебхуков о недоставленных письмах и жалобах, без него вебхуки отключены"`
+}
+
+type Webhook struct {
+	Interval    time.Duration `env:"WEBHOOK_INTERVAL" env-default:"5s" comment:"Интервал проверки очереди вебхуков"`
+	BatchSize   int           `env:"WEBHOOK_BATCH_SIZE" env-default:"50" comment:"Количество вебхуков, одновременно отправляемых за одну проверку"`
+	Timeout     time.Duration `env:"WEBHOOK_TIMEOUT" env-default:"10s" comment:"Таймаут запроса вебхука"`
+	MaxAttempts int           `env:"WEBHOOK_MAX_ATTEMPTS" env-default:"8" comment:"Количество попыток доставки вебхука"`
+	BackoffBase time.Duration `env:"WEBHOOK_BACKOFF_BASE" env-default:"30s" comment:"Задержка перед первым повтором, удваивается с каждой попыткой"`
+	BackoffMax  time.Duration `env:"WEBHOOK_BACKOFF_MAX" env-default:"6h" comment:"Максимальная задержка между попытками"`
+}
+
+type Outbox struct {
+	Interval    time.Duration `env:"OUTBOX_INTERVAL" env-default:"1s" comment:"Интервал проверки очереди событий"`
+	BatchSize   int           `env:"OUTBOX_BATCH_SIZE" env-default:"100" comment:"Количество событий, передаваемых подписчикам за одну проверку"`
+	MaxAttempts int           `env:"OUTBOX_MAX_ATTEMPTS" env-default:"15" comment:"Количество попыток передачи события, после них событие остается в очереди со статусом failed"`
+	BackoffBase time.Duration `env:"OUTBOX_BACKOFF_BASE" env-default:"5s" comment:"Задержка перед первым повтором события, удваивается с каждой попыткой"`
+	BackoffMax  time.Duration `env:"OUTBOX_BACKOFF_MAX" env-default:"1h" comment:"Максимальная задержка между попытками передачи события"`
 }
 
 func MustLoad() *Config {
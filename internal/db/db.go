@@ -1,6 +1,7 @@
 package db
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -12,22 +13,11 @@ import (
 )
 
 func New(cfg config.Database) (*sqlx.DB, error) {
-	location, err := time.LoadLocation(cfg.TimeZone)
+	connStr, err := connString(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("time load location failed: %v", err)
+		return nil, err
 	}
 
-	connStr := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s timezone=%s",
-		cfg.Host,
-		cfg.Port,
-		cfg.User,
-		cfg.Password,
-		cfg.DBName,
-		cfg.SSLMode,
-		location.String(),
-	)
-
 	dbConn, err := sqlx.Connect("postgres", connStr)
 	if err != nil {
 		return nil, fmt.Errorf("db connection failed: %v", err)
@@ -43,6 +33,35 @@ func New(cfg config.Database) (*sqlx.DB, error) {
 	return dbConn, nil
 }
 
+// NewListener creates a LISTEN/NOTIFY listener on its own connection.
+// The listener connects in the background and reconnects when the connection is lost.
+func NewListener(cfg config.Database, eventCallback pq.EventCallbackType) (*pq.Listener, error) {
+	connStr, err := connString(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return pq.NewListener(connStr, time.Second, time.Minute, eventCallback), nil
+}
+
+func connString(cfg config.Database) (string, error) {
+	location, err := time.LoadLocation(cfg.TimeZone)
+	if err != nil {
+		return "", fmt.Errorf("time load location failed: %v", err)
+	}
+
+	return fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s timezone=%s",
+		cfg.Host,
+		cfg.Port,
+		cfg.User,
+		cfg.Password,
+		cfg.DBName,
+		cfg.SSLMode,
+		location.String(),
+	), nil
+}
+
 func IsDuplicate(err error) bool {
 	if pqErr, ok := err.(*pq.Error); ok {
 		if pqErr.Code.Name() == "unique_violation" {
@@ -52,3 +71,18 @@ func IsDuplicate(err error) bool {
 
 	return false
 }
+
+// IsRetryable reports whether err is a serialization failure or a deadlock,
+// the transaction can succeed if run again. Unlike IsDuplicate it looks
+// through wrapped errors, since it is checked after the whole transaction.
+func IsRetryable(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Name() {
+		case "serialization_failure", "deadlock_detected":
+			return true
+		}
+	}
+
+	return false
+}
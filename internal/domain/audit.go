@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AuditActionUserRegister           = "user.register"
+	AuditActionUserLogin              = "user.login"
+	AuditActionUserLoginFailed        = "user.login_failed"
+	AuditActionUserPasswordChange     = "user.password_change"
+	AuditActionUserPasswordResetForce = "user.password_reset_force"
+	AuditActionUserRoleChange         = "user.role_change"
+	AuditActionUserEmailVerify        = "user.email_verify"
+	AuditActionUserImpersonate        = "user.impersonate"
+	AuditActionUserSuspend            = "user.suspend"
+	AuditActionUserBan                = "user.ban"
+	AuditActionUserReinstate          = "user.reinstate"
+	AuditActionReportResolve          = "report.resolve"
+	AuditActionNewsletterIssueCreate  = "newsletter.issue_create"
+	AuditActionNewsletterIssueSend    = "newsletter.issue_send"
+	AuditActionWebhookCreate          = "webhook.create"
+	AuditActionWebhookUpdate          = "webhook.update"
+	AuditActionWebhookDelete          = "webhook.delete"
+	AuditActionWebhookRedeliver       = "webhook.redeliver"
+)
+
+const (
+	AuditTargetUser            = "user"
+	AuditTargetReport          = "report"
+	AuditTargetIssue           = "newsletter_issue"
+	AuditTargetWebhook         = "webhook_endpoint"
+	AuditTargetWebhookDelivery = "webhook_delivery"
+)
+
+// AuditEvent records who did what, ActorID is empty when nobody is logged
+// in, e.g. for a failed login. ImpersonatorID is the admin who acted as
+// ActorID with an impersonation token.
+type AuditEvent struct {
+	ID             uuid.UUID       `db:"id" json:"id"`
+	Action         string          `db:"action" json:"action"`
+	ActorID        *uuid.UUID      `db:"actor_id" json:"actor_id"`
+	ImpersonatorID *uuid.UUID      `db:"impersonator_id" json:"impersonator_id"`
+	IP             string          `db:"ip" json:"ip"`
+	UserAgent      string          `db:"user_agent" json:"user_agent"`
+	TargetType     string          `db:"target_type" json:"target_type"`
+	TargetID       *uuid.UUID      `db:"target_id" json:"target_id"`
+	Details        json.RawMessage `db:"details" json:"details"`
+	CreatedAt      time.Time       `db:"created_at" json:"created_at"`
+}
+
+type AuditEventFilter struct {
+	Action   string
+	ActorID  *uuid.UUID
+	TargetID *uuid.UUID
+	From     *time.Time
+	To       *time.Time
+	Cursor   *Cursor
+	Limit    int
+}
@@ -0,0 +1,13 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cursor points at the last item of a page ordered by creation time and id.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Follow struct {
+	FollowerID uuid.UUID `db:"follower_id" json:"follower_id"`
+	FolloweeID uuid.UUID `db:"followee_id" json:"followee_id"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
+
+// FollowUser is a user in a followers or following list.
+type FollowUser struct {
+	ID         uuid.UUID `db:"id" json:"id"`
+	Username   string    `db:"username" json:"username"`
+	FollowedAt time.Time `db:"followed_at" json:"followed_at"`
+}
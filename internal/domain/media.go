@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MediaStatusProcessing = "processing"
+	MediaStatusReady      = "ready"
+	MediaStatusFailed     = "failed"
+)
+
+type Media struct {
+	ID           uuid.UUID      `db:"id" json:"id"`
+	UserID       uuid.UUID      `db:"user_id" json:"user_id"`
+	StorageKey   string         `db:"storage_key" json:"-"`
+	OriginalName string         `db:"original_name" json:"original_name"`
+	MimeType     string         `db:"mime_type" json:"mime_type"`
+	Size         int64          `db:"size" json:"size"`
+	Status       string         `db:"status" json:"status"`
+	Width        int            `db:"width" json:"width"`
+	Height       int            `db:"height" json:"height"`
+	OriginalKey  *string        `db:"original_key" json:"-"`
+	URL          string         `db:"-" json:"url"`
+	Variants     []MediaVariant `db:"-" json:"variants"`
+	CreatedAt    time.Time      `db:"created_at" json:"created_at"`
+	DeletedAt    *time.Time     `db:"deleted_at" json:"deleted_at"`
+}
+
+type MediaVariant struct {
+	MediaID    uuid.UUID `db:"media_id" json:"-"`
+	Width      int       `db:"width" json:"width"`
+	Height     int       `db:"height" json:"height"`
+	StorageKey string    `db:"storage_key" json:"-"`
+	MimeType   string    `db:"mime_type" json:"mime_type"`
+	URL        string    `db:"-" json:"url"`
+}
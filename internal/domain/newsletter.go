@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	SubscriberStatusPending      = "pending"
+	SubscriberStatusActive       = "active"
+	SubscriberStatusUnsubscribed = "unsubscribed"
+	SubscriberStatusBounced      = "bounced"
+	SubscriberStatusComplained   = "complained"
+)
+
+const (
+	NewsletterIssueStatusDraft   = "draft"
+	NewsletterIssueStatusSending = "sending"
+	NewsletterIssueStatusSent    = "sent"
+)
+
+const (
+	NewsletterDeliveryStatusClaimed = "claimed"
+	NewsletterDeliveryStatusSent    = "sent"
+	NewsletterDeliveryStatusFailed  = "failed"
+)
+
+// Subscriber is a newsletter recipient, it does not need a user account.
+// The address gets issues only after it is confirmed.
+type Subscriber struct {
+	ID               uuid.UUID  `db:"id" json:"id"`
+	Email            string     `db:"email" json:"email"`
+	Status           string     `db:"status" json:"status"`
+	ConfirmTokenHash []byte     `db:"confirm_token_hash" json:"-"`
+	ConfirmExpiresAt *time.Time `db:"confirm_expires_at" json:"confirm_expires_at"`
+	ConfirmedAt      *time.Time `db:"confirmed_at" json:"confirmed_at"`
+	UnsubscribedAt   *time.Time `db:"unsubscribed_at" json:"unsubscribed_at"`
+	DisabledAt       *time.Time `db:"disabled_at" json:"disabled_at"`
+	CreatedAt        time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt        time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+type NewsletterIssue struct {
+	ID            uuid.UUID  `db:"id" json:"id"`
+	Subject       string     `db:"subject" json:"subject"`
+	Text          string     `db:"text_body" json:"text"`
+	HTML          string     `db:"html_body" json:"html"`
+	Status        string     `db:"status" json:"status"`
+	CreatedBy     *uuid.UUID `db:"created_by" json:"created_by"`
+	SendStartedAt *time.Time `db:"send_started_at" json:"send_started_at"`
+	SentAt        *time.Time `db:"sent_at" json:"sent_at"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+}
+
+// NewsletterDelivery is the result of sending an issue to one subscriber.
+// It is claimed until ClaimedUntil before the message is sent, so that
+// replicas do not send the same issue to the same subscriber, and Error is
+// set when the mailer rejected the message.
+type NewsletterDelivery struct {
+	IssueID      uuid.UUID  `db:"issue_id" json:"issue_id"`
+	SubscriberID uuid.UUID  `db:"subscriber_id" json:"subscriber_id"`
+	Status       string     `db:"status" json:"status"`
+	ClaimedUntil *time.Time `db:"claimed_until" json:"claimed_until"`
+	Error        *string    `db:"error" json:"error"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	NotificationKindFollow = "follow"
+)
+
+// NotificationKinds lists all notification kinds users can configure.
+var NotificationKinds = []string{
+	NotificationKindFollow,
+}
+
+const (
+	NotificationFrequencyImmediate = "immediate"
+	NotificationFrequencyDaily     = "daily"
+	NotificationFrequencyWeekly    = "weekly"
+	NotificationFrequencyOff       = "off"
+)
+
+type Notification struct {
+	ID            uuid.UUID  `db:"id" json:"id"`
+	UserID        uuid.UUID  `db:"user_id" json:"user_id"`
+	ActorID       *uuid.UUID `db:"actor_id" json:"actor_id"`
+	ActorUsername *string    `db:"actor_username" json:"actor_username"`
+	Kind          string     `db:"kind" json:"kind"`
+	TargetID      *uuid.UUID `db:"target_id" json:"target_id"`
+	ReadAt        *time.Time `db:"read_at" json:"read_at"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+}
+
+// NotificationSetting is how often the user gets notifications of a kind by email.
+type NotificationSetting struct {
+	UserID    uuid.UUID `db:"user_id" json:"user_id"`
+	Kind      string    `db:"kind" json:"kind"`
+	Frequency string    `db:"frequency" json:"frequency"`
+}
+
+// NotificationEmail is a notification waiting to be sent by email.
+type NotificationEmail struct {
+	Notification
+	Email     string `db:"email" json:"email"`
+	Username  string `db:"username" json:"username"`
+	Frequency string `db:"frequency" json:"frequency"`
+}
+
+// NotificationEmailFilter selects pending notifications of up to Limit
+// recipients and claims them for Lease.
+type NotificationEmailFilter struct {
+	DefaultFrequency string
+	Limit            int
+	Lease            time.Duration
+}
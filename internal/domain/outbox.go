@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EventUserRegistered = "user.registered"
+)
+
+const (
+	OutboxStatusPending = "pending"
+	OutboxStatusFailed  = "failed"
+)
+
+// OutboxEvent is a domain event written in the same transaction as the
+// change it describes, it is deleted once relayed to its subscribers, or
+// left failed when its subscribers keep failing.
+type OutboxEvent struct {
+	ID            uuid.UUID       `db:"id"`
+	Event         string          `db:"event"`
+	Payload       json.RawMessage `db:"payload"`
+	Status        string          `db:"status"`
+	Attempts      int             `db:"attempts"`
+	NextAttemptAt time.Time       `db:"next_attempt_at"`
+	LastError     *string         `db:"last_error"`
+	CreatedAt     time.Time       `db:"created_at"`
+}
@@ -0,0 +1,17 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PasswordReset is a one time token letting the user set a new password,
+// only a hash of the token is stored.
+type PasswordReset struct {
+	TokenHash []byte     `db:"token_hash" json:"-"`
+	UserID    uuid.UUID  `db:"user_id" json:"user_id"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time `db:"used_at" json:"used_at"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+}
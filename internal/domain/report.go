@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ReportTargetUser = "user"
+)
+
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusResolved  = "resolved"
+	ReportStatusDismissed = "dismissed"
+)
+
+type Report struct {
+	ID         uuid.UUID  `db:"id" json:"id"`
+	ReporterID uuid.UUID  `db:"reporter_id" json:"reporter_id"`
+	TargetType string     `db:"target_type" json:"target_type"`
+	TargetID   uuid.UUID  `db:"target_id" json:"target_id"`
+	Reason     string     `db:"reason" json:"reason"`
+	Status     string     `db:"status" json:"status"`
+	ResolvedBy *uuid.UUID `db:"resolved_by" json:"resolved_by"`
+	ResolvedAt *time.Time `db:"resolved_at" json:"resolved_at"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+}
+
+type ReportFilter struct {
+	Status string
+	Cursor *Cursor
+	Limit  int
+}
@@ -6,12 +6,51 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	UserRoleUser  = "user"
+	UserRoleAdmin = "admin"
+)
+
+const (
+	UserStatusActive    = "active"
+	UserStatusSuspended = "suspended"
+	UserStatusBanned    = "banned"
+)
+
 type User struct {
-	ID        uuid.UUID  `db:"id" json:"id"`
-	Username  string     `db:"username" json:"username"`
-	Email     string     `db:"email" json:"email"`
-	Password  []byte     `db:"password" json:"password"`
-	CreatedAt time.Time  `db:"created_at" json:"created_at"`
-	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
-	DeletedAt *time.Time `db:"deleted_at" json:"deleted_at"`
+	ID                    uuid.UUID  `db:"id" json:"id"`
+	Username              string     `db:"username" json:"username"`
+	Email                 string     `db:"email" json:"email"`
+	Password              []byte     `db:"password" json:"password"`
+	Role                  string     `db:"role" json:"role"`
+	Status                string     `db:"status" json:"status"`
+	SuspendedUntil        *time.Time `db:"suspended_until" json:"suspended_until"`
+	EmailVerifiedAt       *time.Time `db:"email_verified_at" json:"email_verified_at"`
+	PasswordResetRequired bool       `db:"password_reset_required" json:"password_reset_required"`
+	CreatedAt             time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt             time.Time  `db:"updated_at" json:"updated_at"`
+	DeletedAt             *time.Time `db:"deleted_at" json:"deleted_at"`
+}
+
+// IsSuspended reports whether the user is suspended at the given time,
+// a suspension ends by itself once its time is over.
+func (u *User) IsSuspended(now time.Time) bool {
+	return u.Status == UserStatusSuspended && u.SuspendedUntil != nil && now.Before(*u.SuspendedUntil)
+}
+
+func (u *User) IsBanned() bool {
+	return u.Status == UserStatusBanned
+}
+
+type UserFilter struct {
+	// Search matches a part of the email or the username.
+	Search string
+	Role   string
+	// Status matches the effective status, a suspension that is over
+	// counts as active.
+	Status      string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Cursor      *Cursor
+	Limit       int
 }
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookEvents lists the events endpoints can subscribe to.
+var WebhookEvents = []string{
+	EventUserRegistered,
+}
+
+const (
+	WebhookDeliveryStatusPending   = "pending"
+	WebhookDeliveryStatusSucceeded = "succeeded"
+	WebhookDeliveryStatusFailed    = "failed"
+)
+
+// WebhookEndpoint is an admin configured URL that gets the events it is
+// subscribed to, signed with its secret.
+type WebhookEndpoint struct {
+	ID        uuid.UUID  `db:"id" json:"id"`
+	URL       string     `db:"url" json:"url"`
+	Secret    string     `db:"secret" json:"-"`
+	Events    []string   `db:"-" json:"events"`
+	Active    bool       `db:"active" json:"active"`
+	CreatedBy *uuid.UUID `db:"created_by" json:"created_by"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// WebhookDelivery is one event queued for one endpoint, it stays pending
+// until it succeeds or runs out of attempts.
+type WebhookDelivery struct {
+	ID             uuid.UUID       `db:"id" json:"id"`
+	EndpointID     uuid.UUID       `db:"endpoint_id" json:"endpoint_id"`
+	Event          string          `db:"event" json:"event"`
+	Payload        json.RawMessage `db:"payload" json:"payload"`
+	Status         string          `db:"status" json:"status"`
+	Attempts       int             `db:"attempts" json:"attempts"`
+	NextAttemptAt  time.Time       `db:"next_attempt_at" json:"next_attempt_at"`
+	LastAttemptAt  *time.Time      `db:"last_attempt_at" json:"last_attempt_at"`
+	ResponseStatus *int            `db:"response_status" json:"response_status"`
+	LastError      *string         `db:"last_error" json:"last_error"`
+	CreatedAt      time.Time       `db:"created_at" json:"created_at"`
+}
+
+type WebhookDeliveryAttempt struct {
+	ID             uuid.UUID `db:"id" json:"id"`
+	DeliveryID     uuid.UUID `db:"delivery_id" json:"delivery_id"`
+	ResponseStatus *int      `db:"response_status" json:"response_status"`
+	Error          *string   `db:"error" json:"error"`
+	DurationMS     int       `db:"duration_ms" json:"duration_ms"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+}
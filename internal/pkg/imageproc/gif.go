@@ -0,0 +1,94 @@
+package imageproc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/gif"
+	"io"
+)
+
+const (
+	gifBlockImage     = 0x2C
+	gifBlockExtension = 0x21
+	gifBlockTrailer   = 0x3B
+)
+
+var errBadGIF = errors.New("malformed gif")
+
+// ReencodeGIF decodes every frame of the GIF and encodes them again. The
+// animation is kept, comments, XMP and other extensions are dropped.
+func ReencodeGIF(w io.Writer, data []byte) error {
+	cfg, err := gif.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("decode gif config failed: %w", err)
+	}
+
+	// every frame is decoded at once, so the limit is for all of them
+	frames, err := gifFrameCount(data)
+	if err != nil {
+		return err
+	}
+	if cfg.Width*cfg.Height*max(frames, 1) > maxPixels {
+		return ErrTooManyPixels
+	}
+
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("decode gif failed: %w", err)
+	}
+
+	return gif.EncodeAll(w, g)
+}
+
+// gifFrameCount counts the frames by walking the blocks of the GIF without
+// decoding them.
+func gifFrameCount(data []byte) (int, error) {
+	// header and logical screen descriptor
+	if len(data) < 13 {
+		return 0, errBadGIF
+	}
+	i := 13
+	if flags := data[10]; flags&0x80 != 0 {
+		i += 3 << (int(flags&0x07) + 1)
+	}
+
+	frames := 0
+	for i < len(data) {
+		switch data[i] {
+		case gifBlockImage:
+			if i+10 > len(data) {
+				return 0, errBadGIF
+			}
+			flags := data[i+9]
+			i += 10
+			if flags&0x80 != 0 {
+				i += 3 << (int(flags&0x07) + 1)
+			}
+			// LZW minimum code size
+			i++
+			frames++
+		case gifBlockExtension:
+			// introducer and label
+			i += 2
+		case gifBlockTrailer:
+			return frames, nil
+		default:
+			return 0, errBadGIF
+		}
+
+		// data sub-blocks up to the empty one
+		for {
+			if i >= len(data) {
+				return 0, errBadGIF
+			}
+			size := int(data[i])
+			i += 1 + size
+			if size == 0 {
+				break
+			}
+		}
+	}
+
+	return frames, nil
+}
@@ -0,0 +1,125 @@
+package imageproc
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+)
+
+// testGIF encodes an animation of frames filled with each color in turn and
+// adds a comment and an XMP extension before the trailer.
+func testGIF(t *testing.T, cfg image.Config, colors ...color.Color) []byte {
+	t.Helper()
+
+	g := &gif.GIF{Config: cfg, LoopCount: 0}
+	for _, c := range colors {
+		frame := image.NewPaletted(image.Rect(0, 0, 4, 3), color.Palette{color.Black, c})
+		for i := range frame.Pix {
+			frame.Pix[i] = 1
+		}
+		g.Image = append(g.Image, frame)
+		g.Delay = append(g.Delay, 10)
+	}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+
+	data := buf.Bytes()
+	trailer := data[len(data)-1]
+
+	data = append(data[:len(data)-1], 0x21, 0xFE, 9)
+	data = append(data, "a comment"...)
+	data = append(data, 0)
+
+	data = append(data, 0x21, 0xFF, 11)
+	data = append(data, "XMP DataXMP"...)
+	data = append(data, 15)
+	data = append(data, "<x:xmpmeta GPS>"...)
+	data = append(data, 0)
+
+	return append(data, trailer)
+}
+
+func TestReencodeGIF(t *testing.T) {
+	red := color.RGBA{0xFF, 0, 0, 0xFF}
+	blue := color.RGBA{0, 0, 0xFF, 0xFF}
+	data := testGIF(t, image.Config{}, red, blue)
+
+	if frames, err := gifFrameCount(data); err != nil || frames != 2 {
+		t.Fatalf("gifFrameCount = %d, %v, want 2", frames, err)
+	}
+
+	var buf bytes.Buffer
+	if err := ReencodeGIF(&buf, data); err != nil {
+		t.Fatalf("ReencodeGIF: %v", err)
+	}
+
+	for _, metadata := range []string{"a comment", "XMP DataXMP", "GPS"} {
+		if bytes.Contains(buf.Bytes(), []byte(metadata)) {
+			t.Errorf("re-encoded gif still has %q", metadata)
+		}
+	}
+
+	g, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatalf("decode re-encoded gif: %v", err)
+	}
+	if len(g.Image) != 2 {
+		t.Fatalf("got %d frames, want 2", len(g.Image))
+	}
+	for i, want := range []color.Color{red, blue} {
+		if got := g.Image[i].At(1, 1); !sameColor(got, want) {
+			t.Errorf("frame %d color = %v, want %v", i, got, want)
+		}
+	}
+	if g.Delay[1] != 10 {
+		t.Errorf("frame delay = %d, want 10", g.Delay[1])
+	}
+}
+
+func TestReencodeGIFLimitsAllFrames(t *testing.T) {
+	// one frame fits in maxPixels, two do not
+	cfg := image.Config{
+		ColorModel: color.Palette{color.Black, color.White},
+		Width:      maxPixels / 1000,
+		Height:     1000,
+	}
+
+	one := testGIF(t, cfg, color.White)
+	if frames, err := gifFrameCount(one); err != nil || frames != 1 {
+		t.Fatalf("gifFrameCount = %d, %v, want 1", frames, err)
+	}
+
+	two := testGIF(t, cfg, color.White, color.Black)
+	if err := ReencodeGIF(&bytes.Buffer{}, two); !errors.Is(err, ErrTooManyPixels) {
+		t.Errorf("ReencodeGIF error = %v, want ErrTooManyPixels", err)
+	}
+}
+
+func TestGIFFrameCountMalformed(t *testing.T) {
+	data := testGIF(t, image.Config{}, color.White)
+
+	for _, bad := range [][]byte{
+		nil,
+		data[:12],
+		// cut inside the image data
+		data[:len(data)/2],
+		// an unknown block instead of the trailer
+		append(bytes.Clone(data[:len(data)-1]), 0x00),
+	} {
+		if _, err := gifFrameCount(bad); err == nil {
+			t.Errorf("gifFrameCount accepted %d bytes of malformed gif", len(bad))
+		}
+	}
+}
+
+func sameColor(a, b color.Color) bool {
+	r1, g1, b1, a1 := a.RGBA()
+	r2, g2, b2, a2 := b.RGBA()
+	return r1 == r2 && g1 == g2 && b1 == b2 && a1 == a2
+}
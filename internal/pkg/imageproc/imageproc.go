@@ -0,0 +1,99 @@
+package imageproc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"golang.org/x/image/draw"
+
+	// register the webp decoder for image.Decode
+	_ "golang.org/x/image/webp"
+)
+
+// maxPixels protects the decoder from images with huge declared dimensions.
+const maxPixels = 50_000_000
+
+var (
+	ErrTooManyPixels     = errors.New("image has too many pixels")
+	ErrUnsupportedFormat = errors.New("unsupported output format")
+)
+
+// DecodeConfig returns the dimensions of the image as it should be displayed,
+// taking EXIF orientation into account.
+func DecodeConfig(data []byte) (width, height int, err error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, fmt.Errorf("decode image config failed: %w", err)
+	}
+
+	if cfg.Width*cfg.Height > maxPixels {
+		return 0, 0, ErrTooManyPixels
+	}
+
+	if swapsDimensions(orientation(data)) {
+		return cfg.Height, cfg.Width, nil
+	}
+
+	return cfg.Width, cfg.Height, nil
+}
+
+// Decode decodes the image and applies its EXIF orientation. Only pixels are
+// kept, so encoding the result drops all metadata including GPS tags.
+func Decode(data []byte) (image.Image, error) {
+	if _, _, err := DecodeConfig(data); err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode image failed: %w", err)
+	}
+
+	return applyOrientation(img, orientation(data)), nil
+}
+
+// Resize scales the image to the given width keeping the aspect ratio.
+func Resize(img image.Image, width int) image.Image {
+	b := img.Bounds()
+	height := b.Dy() * width / b.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+
+	return dst
+}
+
+// Encode writes the image in the format of the given MIME type.
+func Encode(w io.Writer, img image.Image, mimeType string, quality int) error {
+	switch mimeType {
+	case "image/jpeg":
+		return jpeg.Encode(w, flatten(img), &jpeg.Options{Quality: quality})
+	case "image/png":
+		return png.Encode(w, img)
+	}
+
+	return ErrUnsupportedFormat
+}
+
+// flatten draws the image over a white background, JPEG has no alpha channel.
+func flatten(img image.Image) image.Image {
+	if opaque, ok := img.(interface{ Opaque() bool }); ok && opaque.Opaque() {
+		return img
+	}
+
+	b := img.Bounds()
+	dst := image.NewRGBA(b)
+	draw.Draw(dst, b, image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, b, img, b.Min, draw.Over)
+
+	return dst
+}
@@ -0,0 +1,159 @@
+package imageproc
+
+import (
+	"encoding/binary"
+	"image"
+
+	"golang.org/x/image/draw"
+)
+
+const (
+	jpegMarkerSOI  = 0xD8
+	jpegMarkerSOS  = 0xDA
+	jpegMarkerAPP1 = 0xE1
+
+	exifTagOrientation = 0x0112
+)
+
+// orientation reads the EXIF orientation tag of a JPEG image.
+// It returns 1 (normal) when the tag is missing or the data is not a JPEG.
+func orientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != jpegMarkerSOI {
+		return 1
+	}
+
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+
+		marker := data[i+1]
+		if marker == jpegMarkerSOS {
+			return 1
+		}
+
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if size < 2 || i+2+size > len(data) {
+			return 1
+		}
+
+		segment := data[i+4 : i+2+size]
+		if marker == jpegMarkerAPP1 && len(segment) > 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return exifOrientation(segment[6:])
+		}
+
+		i += 2 + size
+	}
+
+	return 1
+}
+
+// exifOrientation looks up the orientation tag in IFD0 of a TIFF structure.
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	offset := int(order.Uint32(tiff[4:]))
+	if offset+2 > len(tiff) {
+		return 1
+	}
+
+	count := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+
+		if order.Uint16(tiff[entry:]) == exifTagOrientation {
+			value := int(order.Uint16(tiff[entry+8:]))
+			if value < 1 || value > 8 {
+				return 1
+			}
+			return value
+		}
+	}
+
+	return 1
+}
+
+// swapsDimensions reports whether the orientation rotates the image by 90 degrees.
+func swapsDimensions(o int) bool {
+	return o >= 5 && o <= 8
+}
+
+// applyOrientation transforms the image so that it is displayed upright.
+// Pixels are moved directly between the pixel slices, the image is
+// converted to RGBA once if it is neither RGBA nor NRGBA.
+func applyOrientation(img image.Image, o int) image.Image {
+	if o < 2 || o > 8 {
+		return img
+	}
+
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	dw, dh := w, h
+	if swapsDimensions(o) {
+		dw, dh = h, w
+	}
+	rect := image.Rect(0, 0, dw, dh)
+
+	// NRGBA is kept as it is, premultiplying would lose precision of
+	// translucent pixels
+	if src, ok := img.(*image.NRGBA); ok {
+		dst := image.NewNRGBA(rect)
+		orientPix(dst.Pix, dst.Stride, src.Pix[src.PixOffset(b.Min.X, b.Min.Y):], src.Stride, w, h, o)
+		return dst
+	}
+
+	src, ok := img.(*image.RGBA)
+	if !ok {
+		src = image.NewRGBA(image.Rect(0, 0, w, h))
+		draw.Draw(src, src.Rect, img, b.Min, draw.Src)
+	}
+
+	dst := image.NewRGBA(rect)
+	orientPix(dst.Pix, dst.Stride, src.Pix[src.PixOffset(src.Rect.Min.X, src.Rect.Min.Y):], src.Stride, w, h, o)
+	return dst
+}
+
+// orientPix copies the w x h image of 4 byte pixels from src to dst with the
+// transform of the orientation.
+func orientPix(dst []byte, dstStride int, src []byte, srcStride, w, h, o int) {
+	for y := 0; y < h; y++ {
+		row := src[y*srcStride : y*srcStride+w*4]
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch o {
+			case 2: // flip horizontal
+				dx, dy = w-1-x, y
+			case 3: // rotate 180
+				dx, dy = w-1-x, h-1-y
+			case 4: // flip vertical
+				dx, dy = x, h-1-y
+			case 5: // transpose
+				dx, dy = y, x
+			case 6: // rotate 90 clockwise
+				dx, dy = h-1-y, x
+			case 7: // transverse
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotate 90 counter clockwise
+				dx, dy = y, w-1-x
+			}
+			i := dy*dstStride + dx*4
+			copy(dst[i:i+4], row[x*4:x*4+4])
+		}
+	}
+}
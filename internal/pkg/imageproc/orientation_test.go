@@ -0,0 +1,169 @@
+package imageproc
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"testing"
+)
+
+type byteOrder interface {
+	binary.ByteOrder
+	binary.AppendByteOrder
+}
+
+type ifdEntry struct {
+	tag   uint16
+	value uint16
+}
+
+// tiffIFD builds a TIFF header followed by IFD0 with SHORT entries.
+func tiffIFD(order byteOrder, entries ...ifdEntry) []byte {
+	b := make([]byte, 8, 8+2+len(entries)*12+4)
+	if order == binary.LittleEndian {
+		copy(b, "II")
+	} else {
+		copy(b, "MM")
+	}
+	order.PutUint16(b[2:], 42)
+	order.PutUint32(b[4:], 8)
+
+	b = order.AppendUint16(b, uint16(len(entries)))
+	for _, e := range entries {
+		b = order.AppendUint16(b, e.tag)
+		b = order.AppendUint16(b, 3) // SHORT
+		b = order.AppendUint32(b, 1)
+		b = order.AppendUint16(b, e.value)
+		b = order.AppendUint16(b, 0)
+	}
+
+	// no next IFD
+	return order.AppendUint32(b, 0)
+}
+
+func TestExifOrientation(t *testing.T) {
+	const width = 0x0100
+
+	pastEnd := tiffIFD(binary.LittleEndian, ifdEntry{exifTagOrientation, 6})
+	binary.LittleEndian.PutUint32(pastEnd[4:], uint32(len(pastEnd)))
+
+	farPastEnd := tiffIFD(binary.BigEndian, ifdEntry{exifTagOrientation, 6})
+	binary.BigEndian.PutUint32(farPastEnd[4:], 0xFFFFFFFF)
+
+	// the count promises a second entry the data does not have
+	truncated := tiffIFD(binary.LittleEndian, ifdEntry{width, 640}, ifdEntry{exifTagOrientation, 6})
+	truncated = truncated[:8+2+12+6]
+
+	tests := []struct {
+		name string
+		tiff []byte
+		want int
+	}{
+		{"little endian", tiffIFD(binary.LittleEndian, ifdEntry{exifTagOrientation, 6}), 6},
+		{"big endian", tiffIFD(binary.BigEndian, ifdEntry{exifTagOrientation, 3}), 3},
+		{"after another tag", tiffIFD(binary.BigEndian, ifdEntry{width, 640}, ifdEntry{exifTagOrientation, 8}), 8},
+		{"no orientation tag", tiffIFD(binary.LittleEndian, ifdEntry{width, 640}), 1},
+		{"no entries", tiffIFD(binary.LittleEndian), 1},
+		{"value 0", tiffIFD(binary.LittleEndian, ifdEntry{exifTagOrientation, 0}), 1},
+		{"value 9", tiffIFD(binary.BigEndian, ifdEntry{exifTagOrientation, 9}), 1},
+		{"value 0xFFFF", tiffIFD(binary.BigEndian, ifdEntry{exifTagOrientation, 0xFFFF}), 1},
+		{"truncated ifd", truncated, 1},
+		{"ifd offset past the end", pastEnd, 1},
+		{"ifd offset far past the end", farPastEnd, 1},
+		{"unknown byte order", append([]byte("XX"), tiffIFD(binary.BigEndian, ifdEntry{exifTagOrientation, 6})[2:]...), 1},
+		{"short header", []byte("II*\x00"), 1},
+		{"empty", nil, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exifOrientation(tt.tiff); got != tt.want {
+				t.Errorf("exifOrientation = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrientationReadsJPEGApp1(t *testing.T) {
+	exif := append([]byte("Exif\x00\x00"), tiffIFD(binary.BigEndian, ifdEntry{exifTagOrientation, 6})...)
+
+	data := []byte{0xFF, jpegMarkerSOI}
+	// an APP0 segment before the EXIF one is skipped
+	data = append(data, 0xFF, 0xE0, 0x00, 0x04, 'J', 'F')
+	data = append(data, 0xFF, jpegMarkerAPP1)
+	data = binary.BigEndian.AppendUint16(data, uint16(2+len(exif)))
+	data = append(data, exif...)
+	data = append(data, 0xFF, jpegMarkerSOS)
+
+	if got := orientation(data); got != 6 {
+		t.Errorf("orientation = %d, want 6", got)
+	}
+
+	// a segment longer than the data
+	if got := orientation(data[:len(data)-10]); got != 1 {
+		t.Errorf("orientation of truncated data = %d, want 1", got)
+	}
+}
+
+func TestApplyOrientation(t *testing.T) {
+	// the source is 3x2:
+	//   a b c
+	//   d e f
+	const a, b, c, d, e, f = 10, 20, 30, 40, 50, 60
+
+	tests := []struct {
+		orientation int
+		want        [][]uint8
+	}{
+		{1, [][]uint8{{a, b, c}, {d, e, f}}},
+		{2, [][]uint8{{c, b, a}, {f, e, d}}},
+		{3, [][]uint8{{f, e, d}, {c, b, a}}},
+		{4, [][]uint8{{d, e, f}, {a, b, c}}},
+		{5, [][]uint8{{a, d}, {b, e}, {c, f}}},
+		{6, [][]uint8{{d, a}, {e, b}, {f, c}}},
+		{7, [][]uint8{{f, c}, {e, b}, {d, a}}},
+		{8, [][]uint8{{c, f}, {b, e}, {a, d}}},
+	}
+
+	// NRGBA is transformed as it is, Gray goes through the RGBA conversion;
+	// both start away from the origin to catch bounds mistakes
+	sources := map[string]func() (image.Image, func(x, y int, v uint8)){
+		"nrgba": func() (image.Image, func(x, y int, v uint8)) {
+			img := image.NewNRGBA(image.Rect(5, 7, 8, 9))
+			return img, func(x, y int, v uint8) { img.Set(5+x, 7+y, color.NRGBA{v, v, v, 0xFF}) }
+		},
+		"gray": func() (image.Image, func(x, y int, v uint8)) {
+			img := image.NewGray(image.Rect(5, 7, 8, 9))
+			return img, func(x, y int, v uint8) { img.Set(5+x, 7+y, color.Gray{v}) }
+		},
+	}
+
+	for name, newSource := range sources {
+		for _, tt := range tests {
+			src, set := newSource()
+			for y, row := range tests[0].want {
+				for x, v := range row {
+					set(x, y, v)
+				}
+			}
+
+			got := applyOrientation(src, tt.orientation)
+
+			wantW, wantH := len(tt.want[0]), len(tt.want)
+			if got.Bounds().Dx() != wantW || got.Bounds().Dy() != wantH {
+				t.Errorf("%s %d: bounds %v, want %dx%d", name, tt.orientation, got.Bounds(), wantW, wantH)
+				continue
+			}
+
+			origin := got.Bounds().Min
+			for y, row := range tt.want {
+				for x, want := range row {
+					r, _, _, _ := got.At(origin.X+x, origin.Y+y).RGBA()
+					if uint8(r>>8) != want {
+						t.Errorf("%s %d: pixel (%d, %d) = %d, want %d", name, tt.orientation, x, y, r>>8, want)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// File writes messages as .eml files into a directory, it is used in local
+// development and tests instead of a real mail server.
+type File struct {
+	dir  string
+	from string
+	seq  atomic.Uint64
+}
+
+func NewFile(dir, from string) (*File, error) {
+	if dir == "" {
+		return nil, errors.New("empty mail dir")
+	}
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("create mail dir failed: %w", err)
+	}
+
+	return &File{
+		dir:  dir,
+		from: from,
+	}, nil
+}
+
+func (f *File) Send(_ context.Context, msg *Message) error {
+	data, err := build(f.from, msg)
+	if err != nil {
+		return err
+	}
+
+	name := time.Now().UTC().Format("20060102T150405.000000000") + "-" + strconv.FormatUint(f.seq.Add(1), 10) + ".eml"
+	if err := os.WriteFile(filepath.Join(f.dir, name), data, 0o600); err != nil {
+		return fmt.Errorf("write mail file failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/newnorthblog/backend/internal/config"
+)
+
+const (
+	backendSMTP = "smtp"
+	backendFile = "file"
+)
+
+// Mailer sends email messages.
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Message is an email with a plain text and an HTML alternative.
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+	Headers map[string]string
+}
+
+// New creates the mailer selected in config.
+func New(cfg config.Mailer) (Mailer, error) {
+	switch cfg.Backend {
+	case backendSMTP:
+		return NewSMTP(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUser, cfg.SMTPPassword, cfg.From)
+	case backendFile:
+		return NewFile(cfg.FileDir, cfg.From)
+	}
+
+	return nil, fmt.Errorf("unknown mailer backend: %q", cfg.Backend)
+}
+
+// build renders the message in RFC 5322 format as multipart/alternative.
+func build(from string, msg *Message) ([]byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	for _, part := range []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("create part failed: %w", err)
+		}
+
+		qw := quotedprintable.NewWriter(pw)
+		if _, err := qw.Write([]byte(part.content)); err != nil {
+			return nil, fmt.Errorf("write part failed: %w", err)
+		}
+		if err := qw.Close(); err != nil {
+			return nil, fmt.Errorf("close part failed: %w", err)
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf("close multipart failed: %w", err)
+	}
+
+	messageID, err := newMessageID(from)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	writeHeader(&out, "From", from)
+	writeHeader(&out, "To", msg.To)
+	writeHeader(&out, "Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	writeHeader(&out, "Date", time.Now().Format(time.RFC1123Z))
+	writeHeader(&out, "Message-ID", messageID)
+	writeHeader(&out, "MIME-Version", "1.0")
+	for k, v := range msg.Headers {
+		writeHeader(&out, k, v)
+	}
+	writeHeader(&out, "Content-Type", "multipart/alternative; boundary="+mw.Boundary())
+	out.WriteString("\r\n")
+	out.Write(body.Bytes())
+
+	return out.Bytes(), nil
+}
+
+func writeHeader(buf *bytes.Buffer, key, value string) {
+	// header injection protection, values never span lines
+	value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+	buf.WriteString(key + ": " + value + "\r\n")
+}
+
+func newMessageID(from string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate message id failed: %w", err)
+	}
+
+	domain := "localhost"
+	if _, d, ok := strings.Cut(from, "@"); ok {
+		domain = strings.TrimRight(d, ">")
+	}
+
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">", nil
+}
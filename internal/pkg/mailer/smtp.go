@@ -0,0 +1,56 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+)
+
+// SMTP sends messages through an SMTP server.
+type SMTP struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+func NewSMTP(host, port, user, password, from string) (*SMTP, error) {
+	if host == "" {
+		return nil, errors.New("empty smtp host")
+	}
+
+	if from == "" {
+		return nil, errors.New("empty sender address")
+	}
+
+	var auth smtp.Auth
+	if user != "" {
+		auth = smtp.PlainAuth("", user, password, host)
+	}
+
+	return &SMTP{
+		addr: net.JoinHostPort(host, port),
+		auth: auth,
+		from: from,
+	}, nil
+}
+
+func (s *SMTP) Send(_ context.Context, msg *Message) error {
+	data, err := build(s.from, msg)
+	if err != nil {
+		return err
+	}
+
+	from, err := mail.ParseAddress(s.from)
+	if err != nil {
+		return fmt.Errorf("parse sender address failed: %w", err)
+	}
+
+	if err := smtp.SendMail(s.addr, s.auth, from.Address, []string{msg.To}, data); err != nil {
+		return fmt.Errorf("send mail failed: %w", err)
+	}
+
+	return nil
+}
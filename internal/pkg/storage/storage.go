@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/newnorthblog/backend/internal/config"
+)
+
+const (
+	backendLocal = "local"
+	backendS3    = "s3"
+)
+
+// ErrNotFound is returned by Get when nothing is stored under the key.
+var ErrNotFound = errors.New("storage: file not found")
+
+// Backend stores uploaded files and resolves their public URLs.
+type Backend interface {
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+// New creates the storage backend selected in config.
+func New(cfg config.Storage) (Backend, error) {
+	switch cfg.Backend {
+	case backendLocal:
+		return NewLocal(cfg.LocalDir, cfg.LocalURL)
+	case backendS3:
+		return NewS3(cfg.S3Endpoint, cfg.S3Region, cfg.S3Bucket, cfg.S3AccessKey, cfg.S3SecretKey, cfg.S3PublicURL)
+	}
+
+	return nil, fmt.Errorf("unknown storage backend: %q", cfg.Backend)
+}
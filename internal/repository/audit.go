@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/newnorthblog/backend/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type auditRepository struct {
+	db *sqlx.DB
+}
+
+func newAuditRepository(db *sqlx.DB) *auditRepository {
+	return &auditRepository{
+		db: db,
+	}
+}
+
+func (r *auditRepository) Create(ctx context.Context, event *domain.AuditEvent) error {
+	const query = `
+	INSERT INTO audit_event
+	(id, action, actor_id, impersonator_id, ip, user_agent, target_type, target_id, details)
+	VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	RETURNING created_at;
+	`
+
+	// details go as text, pq would send a []byte as bytea
+	err := getExecutor(ctx, r.db).QueryRowxContext(ctx, query,
+		event.ID, event.Action, event.ActorID, event.ImpersonatorID, event.IP, event.UserAgent, event.TargetType, event.TargetID, string(event.Details),
+	).Scan(&event.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert audit event failed: %w", err)
+	}
+
+	return nil
+}
+
+func (r *auditRepository) List(ctx context.Context, filter *domain.AuditEventFilter) ([]domain.AuditEvent, error) {
+	const query = `
+	SELECT id, action, actor_id, impersonator_id, ip, user_agent, target_type, target_id, details, created_at
+	FROM audit_event
+	WHERE ($1::text = '' OR action = $1)
+		AND ($2::uuid IS NULL OR actor_id = $2)
+		AND ($3::uuid IS NULL OR target_id = $3)
+		AND ($4::timestamp IS NULL OR created_at >= $4)
+		AND ($5::timestamp IS NULL OR created_at < $5)
+		AND ($6::timestamp IS NULL OR (created_at, id) < ($6, $7))
+	ORDER BY created_at DESC, id DESC
+	LIMIT $8;
+	`
+
+	var (
+		after   *time.Time
+		afterID uuid.UUID
+	)
+	if filter.Cursor != nil {
+		after = &filter.Cursor.CreatedAt
+		afterID = filter.Cursor.ID
+	}
+
+	events := make([]domain.AuditEvent, 0, filter.Limit)
+	if err := getExecutor(ctx, r.db).SelectContext(ctx, &events, query,
+		filter.Action, filter.ActorID, filter.TargetID, filter.From, filter.To, after, afterID, filter.Limit,
+	); err != nil {
+		return nil, fmt.Errorf("select audit events failed: %w", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/newnorthblog/backend/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type followRepository struct {
+	db *sqlx.DB
+}
+
+func newFollowRepository(db *sqlx.DB) *followRepository {
+	return &followRepository{
+		db: db,
+	}
+}
+
+// Create stores the follow and reports whether it did not exist before.
+func (r *followRepository) Create(ctx context.Context, follow *domain.Follow) (bool, error) {
+	const query = `
+	INSERT INTO follow
+	(follower_id, followee_id)
+	VALUES($1, $2)
+	ON CONFLICT DO NOTHING;
+	`
+
+	res, err := getExecutor(ctx, r.db).ExecContext(ctx, query, follow.FollowerID, follow.FolloweeID)
+	if err != nil {
+		return false, fmt.Errorf("insert follow failed: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("get rows affected failed: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+func (r *followRepository) Delete(ctx context.Context, followerID, followeeID uuid.UUID) error {
+	const query = `
+	DELETE FROM follow
+	WHERE follower_id = $1 AND followee_id = $2;
+	`
+
+	if _, err := getExecutor(ctx, r.db).ExecContext(ctx, query, followerID, followeeID); err != nil {
+		return fmt.Errorf("delete follow failed: %w", err)
+	}
+
+	return nil
+}
+
+func (r *followRepository) ListFollowers(ctx context.Context, userID uuid.UUID, cursor *domain.Cursor, limit int) ([]domain.FollowUser, error) {
+	const query = `
+	SELECT u.id, u.username, f.created_at AS followed_at
+	FROM follow f
+	JOIN "user" u ON u.id = f.follower_id
+	WHERE f.followee_id = $1
+		AND ($2::timestamp IS NULL OR (f.created_at, f.follower_id) < ($2, $3))
+	ORDER BY f.created_at DESC, f.follower_id DESC
+	LIMIT $4;
+	`
+
+	return r.list(ctx, query, userID, cursor, limit)
+}
+
+func (r *followRepository) ListFollowing(ctx context.Context, userID uuid.UUID, cursor *domain.Cursor, limit int) ([]domain.FollowUser, error) {
+	const query = `
+	SELECT u.id, u.username, f.created_at AS followed_at
+	FROM follow f
+	JOIN "user" u ON u.id = f.followee_id
+	WHERE f.follower_id = $1
+		AND ($2::timestamp IS NULL OR (f.created_at, f.followee_id) < ($2, $3))
+	ORDER BY f.created_at DESC, f.followee_id DESC
+	LIMIT $4;
+	`
+
+	return r.list(ctx, query, userID, cursor, limit)
+}
+
+func (r *followRepository) list(ctx context.Context, query string, userID uuid.UUID, cursor *domain.Cursor, limit int) ([]domain.FollowUser, error) {
+	var (
+		after   *time.Time
+		afterID uuid.UUID
+	)
+	if cursor != nil {
+		after = &cursor.CreatedAt
+		afterID = cursor.ID
+	}
+
+	users := make([]domain.FollowUser, 0, limit)
+	if err := getExecutor(ctx, r.db).SelectContext(ctx, &users, query, userID, after, afterID, limit); err != nil {
+		return nil, fmt.Errorf("select follows failed: %w", err)
+	}
+
+	return users, nil
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/newnorthblog/backend/internal/db"
+	"github.com/newnorthblog/backend/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type mediaRepository struct {
+	db *sqlx.DB
+}
+
+func newMediaRepository(db *sqlx.DB) *mediaRepository {
+	return &mediaRepository{
+		db: db,
+	}
+}
+
+func (r *mediaRepository) Create(ctx context.Context, media *domain.Media) error {
+	const mediaQuery = `
+	INSERT INTO media
+	(id, user_id, storage_key, original_name, mime_type, size, status, width, height, original_key)
+	VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	RETURNING created_at;
+	`
+	const variantQuery = `
+	INSERT INTO media_variant
+	(media_id, width, height, storage_key, mime_type)
+	VALUES($1, $2, $3, $4, $5);
+	`
+
+	return withinTx(ctx, r.db, func(ctx context.Context) error {
+		tx := getExecutor(ctx, r.db)
+
+		err := tx.QueryRowxContext(ctx, mediaQuery,
+			media.ID, media.UserID, media.StorageKey, media.OriginalName, media.MimeType, media.Size,
+			media.Status, media.Width, media.Height, media.OriginalKey,
+		).Scan(&media.CreatedAt)
+		if err != nil {
+			if db.IsDuplicate(err) {
+				return domain.ErrDuplicateEntry
+			}
+			return fmt.Errorf("insert media failed: %w", err)
+		}
+
+		for _, v := range media.Variants {
+			if _, err := tx.ExecContext(ctx, variantQuery, media.ID, v.Width, v.Height, v.StorageKey, v.MimeType); err != nil {
+				return fmt.Errorf("insert media variant failed: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *mediaRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Media, error) {
+	const query = `
+	SELECT id, user_id, storage_key, original_name, mime_type, size, status, width, height, original_key,
+		created_at, deleted_at
+	FROM media
+	WHERE id = $1 AND deleted_at IS NULL;
+	`
+
+	var media domain.Media
+	if err := getExecutor(ctx, r.db).GetContext(ctx, &media, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("select media failed: %w", err)
+	}
+
+	list := []domain.Media{media}
+	if err := r.loadVariants(ctx, list); err != nil {
+		return nil, err
+	}
+
+	return &list[0], nil
+}
+
+// ListProcessing returns the media still waiting for their images to be
+// processed, oldest first.
+func (r *mediaRepository) ListProcessing(ctx context.Context) ([]domain.Media, error) {
+	const query = `
+	SELECT id, user_id, storage_key, original_name, mime_type, size, status, width, height, original_key,
+		created_at, deleted_at
+	FROM media
+	WHERE status = 'processing'
+	ORDER BY created_at;
+	`
+
+	var media []domain.Media
+	if err := getExecutor(ctx, r.db).SelectContext(ctx, &media, query); err != nil {
+		return nil, fmt.Errorf("select processing media failed: %w", err)
+	}
+
+	if err := r.loadVariants(ctx, media); err != nil {
+		return nil, err
+	}
+
+	return media, nil
+}
+
+func (r *mediaRepository) loadVariants(ctx context.Context, media []domain.Media) error {
+	const query = `
+	SELECT media_id, width, height, storage_key, mime_type
+	FROM media_variant
+	WHERE media_id = ANY($1::uuid[])
+	ORDER BY media_id, width;
+	`
+
+	if len(media) == 0 {
+		return nil
+	}
+
+	idStrs := make([]string, len(media))
+	index := make(map[uuid.UUID]int, len(media))
+	for i := range media {
+		idStrs[i] = media[i].ID.String()
+		index[media[i].ID] = i
+	}
+
+	var variants []domain.MediaVariant
+	if err := getExecutor(ctx, r.db).SelectContext(ctx, &variants, query, pq.Array(idStrs)); err != nil {
+		return fmt.Errorf("select media variants failed: %w", err)
+	}
+
+	for _, v := range variants {
+		i := index[v.MediaID]
+		media[i].Variants = append(media[i].Variants, v)
+	}
+
+	return nil
+}
+
+// UpdateStatus finishes processing of the media, the original upload is not
+// needed anymore. Media already finished by another replica are left as they
+// are and domain.ErrNoRowsAffected is returned.
+func (r *mediaRepository) UpdateStatus(ctx context.Context, media *domain.Media) error {
+	const query = `
+	UPDATE media
+	SET status = $2, size = $3, original_key = NULL
+	WHERE id = $1 AND status = 'processing';
+	`
+
+	res, err := getExecutor(ctx, r.db).ExecContext(ctx, query, media.ID, media.Status, media.Size)
+	if err != nil {
+		return fmt.Errorf("update media status failed: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected failed: %w", err)
+	}
+
+	if affected == 0 {
+		return domain.ErrNoRowsAffected
+	}
+
+	return nil
+}
@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/newnorthblog/backend/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type newsletterIssueRepository struct {
+	db *sqlx.DB
+}
+
+func newNewsletterIssueRepository(db *sqlx.DB) *newsletterIssueRepository {
+	return &newsletterIssueRepository{
+		db: db,
+	}
+}
+
+func (r *newsletterIssueRepository) Create(ctx context.Context, issue *domain.NewsletterIssue) error {
+	const query = `
+	INSERT INTO newsletter_issue
+	(id, subject, text_body, html_body, status, created_by)
+	VALUES($1, $2, $3, $4, $5, $6)
+	RETURNING created_at;
+	`
+
+	err := getExecutor(ctx, r.db).QueryRowxContext(ctx, query,
+		issue.ID, issue.Subject, issue.Text, issue.HTML, issue.Status, issue.CreatedBy,
+	).Scan(&issue.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert newsletter issue failed: %w", err)
+	}
+
+	return nil
+}
+
+func (r *newsletterIssueRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.NewsletterIssue, error) {
+	const query = `
+	SELECT id, subject, text_body, html_body, status, created_by, send_started_at, sent_at, created_at
+	FROM newsletter_issue
+	WHERE id = $1;
+	`
+
+	var issue domain.NewsletterIssue
+	if err := getExecutor(ctx, r.db).GetContext(ctx, &issue, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("select newsletter issue failed: %w", err)
+	}
+
+	return &issue, nil
+}
+
+func (r *newsletterIssueRepository) List(ctx context.Context, cursor *domain.Cursor, limit int) ([]domain.NewsletterIssue, error) {
+	const query = `
+	SELECT id, subject, text_body, html_body, status, created_by, send_started_at, sent_at, created_at
+	FROM newsletter_issue
+	WHERE ($1::timestamp IS NULL OR (created_at, id) < ($1, $2))
+	ORDER BY created_at DESC, id DESC
+	LIMIT $3;
+	`
+
+	var (
+		after   *time.Time
+		afterID uuid.UUID
+	)
+	if cursor != nil {
+		after = &cursor.CreatedAt
+		afterID = cursor.ID
+	}
+
+	issues := make([]domain.NewsletterIssue, 0, limit)
+	if err := getExecutor(ctx, r.db).SelectContext(ctx, &issues, query, after, afterID, limit); err != nil {
+		return nil, fmt.Errorf("select newsletter issues failed: %w", err)
+	}
+
+	return issues, nil
+}
+
+// StartSending moves a draft to sending, it returns domain.ErrNoRowsAffected
+// when the issue is not a draft anymore.
+func (r *newsletterIssueRepository) StartSending(ctx context.Context, issue *domain.NewsletterIssue) error {
+	const query = `
+	UPDATE newsletter_issue
+	SET status = 'sending', send_started_at = NOW()
+	WHERE id = $1 AND status = 'draft'
+	RETURNING status, send_started_at;
+	`
+
+	err := getExecutor(ctx, r.db).QueryRowxContext(ctx, query, issue.ID).Scan(&issue.Status, &issue.SendStartedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.ErrNoRowsAffected
+		}
+		return fmt.Errorf("start sending newsletter issue failed: %w", err)
+	}
+
+	return nil
+}
+
+func (r *newsletterIssueRepository) ListSending(ctx context.Context) ([]domain.NewsletterIssue, error) {
+	const query = `
+	SELECT id, subject, text_body, html_body, status, created_by, send_started_at, sent_at, created_at
+	FROM newsletter_issue
+	WHERE status = 'sending'
+	ORDER BY send_started_at;
+	`
+
+	var issues []domain.NewsletterIssue
+	if err := getExecutor(ctx, r.db).SelectContext(ctx, &issues, query); err != nil {
+		return nil, fmt.Errorf("select sending newsletter issues failed: %w", err)
+	}
+
+	return issues, nil
+}
+
+// MarkSent finishes the issue once every active subscriber has a completed
+// delivery of it, the issue stays sending while anyone is left or still
+// claimed, so that a claim that runs out is taken over.
+func (r *newsletterIssueRepository) MarkSent(ctx context.Context, id uuid.UUID) error {
+	const query = `
+	UPDATE newsletter_issue
+	SET status = 'sent', sent_at = NOW()
+	WHERE id = $1 AND status = 'sending'
+		AND NOT EXISTS (
+			SELECT 1 FROM subscriber s
+			WHERE s.status = 'active'
+				AND NOT EXISTS (
+					SELECT 1 FROM newsletter_delivery d
+					WHERE d.issue_id = $1 AND d.subscriber_id = s.id AND d.status <> 'claimed'
+				)
+		);
+	`
+
+	if _, err := getExecutor(ctx, r.db).ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("mark newsletter issue sent failed: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimRecipients claims up to limit active subscribers for lease that have
+// no delivery of the issue yet, or whose claim has run out, and returns them.
+// Subscribers locked or claimed by another replica are skipped, so that
+// every subscriber is sent the issue by one replica at a time.
+func (r *newsletterIssueRepository) ClaimRecipients(ctx context.Context, issueID uuid.UUID, limit int, lease time.Duration) ([]domain.Subscriber, error) {
+	const query = `
+	WITH candidate AS (
+		SELECT s.id FROM subscriber s
+		WHERE s.status = 'active'
+			AND NOT EXISTS (
+				SELECT 1 FROM newsletter_delivery d
+				WHERE d.issue_id = $1 AND d.subscriber_id = s.id
+					AND (d.status <> 'claimed' OR d.claimed_until > NOW())
+			)
+		ORDER BY s.id
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	), claimed AS (
+		INSERT INTO newsletter_delivery
+		(issue_id, subscriber_id, status, claimed_until)
+		SELECT $1, id, 'claimed', NOW() + make_interval(secs => $3) FROM candidate
+		ON CONFLICT (issue_id, subscriber_id) DO UPDATE
+		SET claimed_until = EXCLUDED.claimed_until
+		WHERE newsletter_delivery.status = 'claimed' AND newsletter_delivery.claimed_until <= NOW()
+		RETURNING subscriber_id
+	)
+	SELECT s.id, s.email, s.status, s.confirm_token_hash, s.confirm_expires_at, s.confirmed_at, s.unsubscribed_at,
+		s.disabled_at, s.created_at, s.updated_at
+	FROM subscriber s
+	JOIN claimed c ON c.subscriber_id = s.id
+	ORDER BY s.id;
+	`
+
+	subscribers := make([]domain.Subscriber, 0, limit)
+	if err := getExecutor(ctx, r.db).SelectContext(ctx, &subscribers, query, issueID, limit, lease.Seconds()); err != nil {
+		return nil, fmt.Errorf("claim newsletter recipients failed: %w", err)
+	}
+
+	return subscribers, nil
+}
+
+// ReleaseRecipients drops claims of subscribers the issue was not sent to,
+// so that they are claimed again later.
+func (r *newsletterIssueRepository) ReleaseRecipients(ctx context.Context, issueID uuid.UUID, subscriberIDs []uuid.UUID) error {
+	const query = `
+	DELETE FROM newsletter_delivery
+	WHERE issue_id = $1 AND subscriber_id = ANY($2::uuid[]) AND status = 'claimed';
+	`
+
+	idStrs := make([]string, len(subscriberIDs))
+	for i, id := range subscriberIDs {
+		idStrs[i] = id.String()
+	}
+
+	if _, err := getExecutor(ctx, r.db).ExecContext(ctx, query, issueID, pq.Array(idStrs)); err != nil {
+		return fmt.Errorf("delete newsletter deliveries failed: %w", err)
+	}
+
+	return nil
+}
+
+// CompleteDelivery records whether the claimed delivery was sent.
+func (r *newsletterIssueRepository) CompleteDelivery(ctx context.Context, delivery *domain.NewsletterDelivery) error {
+	const query = `
+	UPDATE newsletter_delivery
+	SET status = $3, error = $4, claimed_until = NULL
+	WHERE issue_id = $1 AND subscriber_id = $2;
+	`
+
+	if _, err := getExecutor(ctx, r.db).ExecContext(ctx, query,
+		delivery.IssueID, delivery.SubscriberID, delivery.Status, delivery.Error,
+	); err != nil {
+		return fmt.Errorf("update newsletter delivery failed: %w", err)
+	}
+
+	return nil
+}
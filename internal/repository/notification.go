@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/newnorthblog/backend/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type notificationRepository struct {
+	db *sqlx.DB
+}
+
+func newNotificationRepository(db *sqlx.DB) *notificationRepository {
+	return &notificationRepository{
+		db: db,
+	}
+}
+
+func (r *notificationRepository) Create(ctx context.Context, notification *domain.Notification) error {
+	const query = `
+	INSERT INTO notification
+	(id, user_id, actor_id, kind, target_id)
+	VALUES($1, $2, $3, $4, $5)
+	RETURNING created_at;
+	`
+
+	err := getExecutor(ctx, r.db).QueryRowxContext(ctx, query,
+		notification.ID, notification.UserID, notification.ActorID, notification.Kind, notification.TargetID,
+	).Scan(&notification.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert notification failed: %w", err)
+	}
+
+	return nil
+}
+
+func (r *notificationRepository) List(ctx context.Context, userID uuid.UUID, cursor *domain.Cursor, limit int) ([]domain.Notification, error) {
+	const query = `
+	SELECT n.id, n.user_id, n.actor_id, a.username AS actor_username, n.kind, n.target_id, n.read_at, n.created_at
+	FROM notification n
+	LEFT JOIN "user" a ON a.id = n.actor_id
+	WHERE n.user_id = $1
+		AND ($2::timestamp IS NULL OR (n.created_at, n.id) < ($2, $3))
+	ORDER BY n.created_at DESC, n.id DESC
+	LIMIT $4;
+	`
+
+	var (
+		after   *time.Time
+		afterID uuid.UUID
+	)
+	if cursor != nil {
+		after = &cursor.CreatedAt
+		afterID = cursor.ID
+	}
+
+	notifications := make([]domain.Notification, 0, limit)
+	if err := getExecutor(ctx, r.db).SelectContext(ctx, &notifications, query, userID, after, afterID, limit); err != nil {
+		return nil, fmt.Errorf("select notifications failed: %w", err)
+	}
+
+	return notifications, nil
+}
+
+func (r *notificationRepository) CountUnread(ctx context.Context, userID uuid.UUID) (int, error) {
+	const query = `
+	SELECT COUNT(*)
+	FROM notification
+	WHERE user_id = $1 AND read_at IS NULL;
+	`
+
+	var count int
+	if err := getExecutor(ctx, r.db).GetContext(ctx, &count, query, userID); err != nil {
+		return 0, fmt.Errorf("count unread notifications failed: %w", err)
+	}
+
+	return count, nil
+}
+
+// MarkRead marks the given notifications of the user as read, all of them when ids is empty.
+func (r *notificationRepository) MarkRead(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) error {
+	const query = `
+	UPDATE notification
+	SET read_at = NOW()
+	WHERE user_id = $1 AND read_at IS NULL
+		AND (cardinality($2::uuid[]) = 0 OR id = ANY($2));
+	`
+
+	idStrs := make([]string, len(ids))
+	for i, id := range ids {
+		idStrs[i] = id.String()
+	}
+
+	if _, err := getExecutor(ctx, r.db).ExecContext(ctx, query, userID, pq.Array(idStrs)); err != nil {
+		return fmt.Errorf("mark notifications read failed: %w", err)
+	}
+
+	return nil
+}
+
+func (r *notificationRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Notification, error) {
+	const query = `
+	SELECT n.id, n.user_id, n.actor_id, a.username AS actor_username, n.kind, n.target_id, n.read_at, n.created_at
+	FROM notification n
+	LEFT JOIN "user" a ON a.id = n.actor_id
+	WHERE n.id = $1;
+	`
+
+	var notification domain.Notification
+	if err := getExecutor(ctx, r.db).GetContext(ctx, &notification, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("select notification failed: %w", err)
+	}
+
+	return &notification, nil
+}
+
+// ListAfter returns notifications of the user created after the given one, oldest first.
+func (r *notificationRepository) ListAfter(ctx context.Context, userID, afterID uuid.UUID, limit int) ([]domain.Notification, error) {
+	const query = `
+	SELECT n.id, n.user_id, n.actor_id, a.username AS actor_username, n.kind, n.target_id, n.read_at, n.created_at
+	FROM notification n
+	LEFT JOIN "user" a ON a.id = n.actor_id
+	WHERE n.user_id = $1
+		AND (n.created_at, n.id) > (SELECT created_at, id FROM notification WHERE id = $2 AND user_id = $1)
+	ORDER BY n.created_at, n.id
+	LIMIT $3;
+	`
+
+	notifications := make([]domain.Notification, 0, limit)
+	if err := getExecutor(ctx, r.db).SelectContext(ctx, &notifications, query, userID, afterID, limit); err != nil {
+		return nil, fmt.Errorf("select notifications failed: %w", err)
+	}
+
+	return notifications, nil
+}
+
+// ClaimEmailPending claims notifications not handled by email yet whose
+// digest is due and returns them: immediate ones, daily and weekly ones once
+// the oldest unread notification of the digest is a day or a week old, and
+// the ones that need no email because they are read or emails are off.
+//
+// The limit counts recipients, so that a digest is never split between two
+// claims. Claimed notifications are skipped by other replicas until the
+// lease runs out or they are marked emailed. Rows are ordered by recipient
+// and frequency so that a digest is contiguous.
+func (r *notificationRepository) ClaimEmailPending(ctx context.Context, filter *domain.NotificationEmailFilter) ([]domain.NotificationEmail, error) {
+	const query = `
+	WITH pending AS (
+		SELECT n.id, n.user_id, n.read_at,
+			COALESCE(s.frequency, $1) AS frequency,
+			MIN(n.created_at) FILTER (WHERE n.read_at IS NULL)
+				OVER (PARTITION BY n.user_id, COALESCE(s.frequency, $1)) AS oldest_unread
+		FROM notification n
+		LEFT JOIN notification_setting s ON s.user_id = n.user_id AND s.kind = n.kind
+		WHERE n.emailed_at IS NULL
+			AND (n.email_claimed_until IS NULL OR n.email_claimed_until <= NOW())
+	), due AS (
+		SELECT id, user_id, frequency
+		FROM pending
+		WHERE read_at IS NOT NULL
+			OR frequency = 'off'
+			OR frequency = 'immediate'
+			OR (frequency = 'daily' AND oldest_unread <= NOW() - INTERVAL '1 day')
+			OR (frequency = 'weekly' AND oldest_unread <= NOW() - INTERVAL '7 days')
+	), recipient AS (
+		SELECT DISTINCT user_id
+		FROM due
+		ORDER BY user_id
+		LIMIT $2
+	), claimed AS (
+		UPDATE notification
+		SET email_claimed_until = NOW() + make_interval(secs => $3)
+		WHERE id IN (
+			SELECT id FROM notification
+			WHERE id IN (SELECT d.id FROM due d JOIN recipient r ON r.user_id = d.user_id)
+				AND emailed_at IS NULL
+				AND (email_claimed_until IS NULL OR email_claimed_until <= NOW())
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, user_id, actor_id, kind, target_id, read_at, created_at
+	)
+	SELECT c.id, c.user_id, c.actor_id, a.username AS actor_username, c.kind, c.target_id, c.read_at, c.created_at,
+		u.email, u.username, d.frequency
+	FROM claimed c
+	JOIN due d ON d.id = c.id
+	JOIN "user" u ON u.id = c.user_id
+	LEFT JOIN "user" a ON a.id = c.actor_id
+	ORDER BY c.user_id, d.frequency, c.created_at, c.id;
+	`
+
+	var notifications []domain.NotificationEmail
+	if err := getExecutor(ctx, r.db).SelectContext(ctx, &notifications, query,
+		filter.DefaultFrequency, filter.Limit, filter.Lease.Seconds(),
+	); err != nil {
+		return nil, fmt.Errorf("claim pending email notifications failed: %w", err)
+	}
+
+	return notifications, nil
+}
+
+func (r *notificationRepository) MarkEmailed(ctx context.Context, ids []uuid.UUID) error {
+	const query = `
+	UPDATE notification
+	SET emailed_at = NOW()
+	WHERE id = ANY($1);
+	`
+
+	idStrs := make([]string, len(ids))
+	for i, id := range ids {
+		idStrs[i] = id.String()
+	}
+
+	if _, err := getExecutor(ctx, r.db).ExecContext(ctx, query, pq.Array(idStrs)); err != nil {
+		return fmt.Errorf("mark notifications emailed failed: %w", err)
+	}
+
+	return nil
+}
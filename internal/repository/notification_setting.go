@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/newnorthblog/backend/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type notificationSettingRepository struct {
+	db *sqlx.DB
+}
+
+func newNotificationSettingRepository(db *sqlx.DB) *notificationSettingRepository {
+	return &notificationSettingRepository{
+		db: db,
+	}
+}
+
+func (r *notificationSettingRepository) List(ctx context.Context, userID uuid.UUID) ([]domain.NotificationSetting, error) {
+	const query = `
+	SELECT user_id, kind, frequency
+	FROM notification_setting
+	WHERE user_id = $1;
+	`
+
+	var settings []domain.NotificationSetting
+	if err := getExecutor(ctx, r.db).SelectContext(ctx, &settings, query, userID); err != nil {
+		return nil, fmt.Errorf("select notification settings failed: %w", err)
+	}
+
+	return settings, nil
+}
+
+func (r *notificationSettingRepository) Upsert(ctx context.Context, settings []domain.NotificationSetting) error {
+	const query = `
+	INSERT INTO notification_setting
+	(user_id, kind, frequency)
+	VALUES($1, $2, $3)
+	ON CONFLICT (user_id, kind) DO UPDATE
+	SET frequency = EXCLUDED.frequency, updated_at = NOW();
+	`
+
+	return withinTx(ctx, r.db, func(ctx context.Context) error {
+		for _, s := range settings {
+			if _, err := getExecutor(ctx, r.db).ExecContext(ctx, query, s.UserID, s.Kind, s.Frequency); err != nil {
+				return fmt.Errorf("upsert notification setting failed: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/newnorthblog/backend/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type outboxRepository struct {
+	db *sqlx.DB
+}
+
+func newOutboxRepository(db *sqlx.DB) *outboxRepository {
+	return &outboxRepository{
+		db: db,
+	}
+}
+
+func (r *outboxRepository) Create(ctx context.Context, event *domain.OutboxEvent) error {
+	const query = `
+	INSERT INTO outbox
+	(id, event, payload)
+	VALUES($1, $2, $3)
+	RETURNING status, next_attempt_at, created_at;
+	`
+
+	// payload goes as text, pq would send a []byte as bytea
+	err := getExecutor(ctx, r.db).QueryRowxContext(ctx, query, event.ID, event.Event, string(event.Payload)).
+		Scan(&event.Status, &event.NextAttemptAt, &event.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert outbox event failed: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimNext locks the oldest due pending event until the end of the
+// transaction in ctx, events locked by other relays are skipped. It returns
+// domain.ErrNotFound when nothing is due.
+func (r *outboxRepository) ClaimNext(ctx context.Context) (*domain.OutboxEvent, error) {
+	const query = `
+	SELECT id, event, payload, status, attempts, next_attempt_at, last_error, created_at
+	FROM outbox
+	WHERE status = 'pending' AND next_attempt_at <= NOW()
+	ORDER BY next_attempt_at, id
+	LIMIT 1
+	FOR UPDATE SKIP LOCKED;
+	`
+
+	var event domain.OutboxEvent
+	if err := getExecutor(ctx, r.db).GetContext(ctx, &event, query); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("select outbox event failed: %w", err)
+	}
+
+	return &event, nil
+}
+
+// Delete removes a relayed event, in the relay transaction so that it goes
+// together with whatever its subscribers wrote.
+func (r *outboxRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	const query = `
+	DELETE FROM outbox
+	WHERE id = $1;
+	`
+
+	if _, err := getExecutor(ctx, r.db).ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("delete outbox event failed: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFailed counts a failed relay attempt and puts the event off for
+// retryIn, the time is taken from the database clock like in ClaimNext.
+func (r *outboxRepository) MarkFailed(ctx context.Context, id uuid.UUID, retryIn time.Duration, lastError string) error {
+	const query = `
+	UPDATE outbox
+	SET attempts = attempts + 1, next_attempt_at = NOW() + make_interval(secs => $2), last_error = $3
+	WHERE id = $1;
+	`
+
+	if _, err := getExecutor(ctx, r.db).ExecContext(ctx, query, id, retryIn.Seconds(), lastError); err != nil {
+		return fmt.Errorf("update outbox event failed: %w", err)
+	}
+
+	return nil
+}
+
+// Abandon counts the last failed attempt and stops relaying the event, it
+// stays in the outbox as failed.
+func (r *outboxRepository) Abandon(ctx context.Context, id uuid.UUID, lastError string) error {
+	const query = `
+	UPDATE outbox
+	SET status = 'failed', attempts = attempts + 1, last_error = $2
+	WHERE id = $1;
+	`
+
+	if _, err := getExecutor(ctx, r.db).ExecContext(ctx, query, id, lastError); err != nil {
+		return fmt.Errorf("update outbox event failed: %w", err)
+	}
+
+	return nil
+}
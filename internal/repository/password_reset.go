@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/newnorthblog/backend/internal/domain"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type passwordResetRepository struct {
+	db *sqlx.DB
+}
+
+func newPasswordResetRepository(db *sqlx.DB) *passwordResetRepository {
+	return &passwordResetRepository{
+		db: db,
+	}
+}
+
+func (r *passwordResetRepository) Create(ctx context.Context, reset *domain.PasswordReset) error {
+	const query = `
+	INSERT INTO password_reset
+	(token_hash, user_id, expires_at)
+	VALUES($1, $2, $3)
+	RETURNING created_at;
+	`
+
+	err := getExecutor(ctx, r.db).QueryRowxContext(ctx, query, reset.TokenHash, reset.UserID, reset.ExpiresAt.UTC()).Scan(&reset.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert password reset failed: %w", err)
+	}
+
+	return nil
+}
+
+// Use marks an unused and unexpired token used and returns it, any other
+// token gives domain.ErrNotFound.
+func (r *passwordResetRepository) Use(ctx context.Context, tokenHash []byte) (*domain.PasswordReset, error) {
+	const query = `
+	UPDATE password_reset
+	SET used_at = NOW()
+	WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+	RETURNING token_hash, user_id, expires_at, used_at, created_at;
+	`
+
+	var reset domain.PasswordReset
+	if err := getExecutor(ctx, r.db).GetContext(ctx, &reset, query, tokenHash); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("use password reset failed: %w", err)
+	}
+
+	return &reset, nil
+}
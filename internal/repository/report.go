@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/newnorthblog/backend/internal/db"
+	"github.com/newnorthblog/backend/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type reportRepository struct {
+	db *sqlx.DB
+}
+
+func newReportRepository(db *sqlx.DB) *reportRepository {
+	return &reportRepository{
+		db: db,
+	}
+}
+
+func (r *reportRepository) Create(ctx context.Context, report *domain.Report) error {
+	const query = `
+	INSERT INTO report
+	(id, reporter_id, target_type, target_id, reason, status)
+	VALUES($1, $2, $3, $4, $5, $6)
+	RETURNING created_at;
+	`
+
+	err := getExecutor(ctx, r.db).QueryRowxContext(ctx, query,
+		report.ID, report.ReporterID, report.TargetType, report.TargetID, report.Reason, report.Status,
+	).Scan(&report.CreatedAt)
+	if err != nil {
+		if db.IsDuplicate(err) {
+			return domain.ErrDuplicateEntry
+		}
+		return fmt.Errorf("insert report failed: %w", err)
+	}
+
+	return nil
+}
+
+func (r *reportRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Report, error) {
+	const query = `
+	SELECT id, reporter_id, target_type, target_id, reason, status, resolved_by, resolved_at, created_at
+	FROM report
+	WHERE id = $1;
+	`
+
+	var report domain.Report
+	if err := getExecutor(ctx, r.db).GetContext(ctx, &report, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("select report failed: %w", err)
+	}
+
+	return &report, nil
+}
+
+func (r *reportRepository) List(ctx context.Context, filter *domain.ReportFilter) ([]domain.Report, error) {
+	const query = `
+	SELECT id, reporter_id, target_type, target_id, reason, status, resolved_by, resolved_at, created_at
+	FROM report
+	WHERE ($1::text = '' OR status = $1)
+		AND ($2::timestamp IS NULL OR (created_at, id) < ($2, $3))
+	ORDER BY created_at DESC, id DESC
+	LIMIT $4;
+	`
+
+	var (
+		after   *time.Time
+		afterID uuid.UUID
+	)
+	if filter.Cursor != nil {
+		after = &filter.Cursor.CreatedAt
+		afterID = filter.Cursor.ID
+	}
+
+	reports := make([]domain.Report, 0, filter.Limit)
+	if err := getExecutor(ctx, r.db).SelectContext(ctx, &reports, query, filter.Status, after, afterID, filter.Limit); err != nil {
+		return nil, fmt.Errorf("select reports failed: %w", err)
+	}
+
+	return reports, nil
+}
+
+// Resolve closes an open report, it returns domain.ErrNoRowsAffected when the
+// report is not open anymore.
+func (r *reportRepository) Resolve(ctx context.Context, report *domain.Report) error {
+	const query = `
+	UPDATE report
+	SET status = $2, resolved_by = $3, resolved_at = NOW()
+	WHERE id = $1 AND status = 'open'
+	RETURNING resolved_at;
+	`
+
+	err := getExecutor(ctx, r.db).QueryRowxContext(ctx, query, report.ID, report.Status, report.ResolvedBy).Scan(&report.ResolvedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.ErrNoRowsAffected
+		}
+		return fmt.Errorf("resolve report failed: %w", err)
+	}
+
+	return nil
+}
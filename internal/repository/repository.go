@@ -2,23 +2,150 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/newnorthblog/backend/internal/domain"
 
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 )
 
 type Repositories struct {
+	TxManager
 	Users
+	Media
+	Follows
+	Notifications
+	NotificationSettings
+	Reports
+	PasswordResets
+	AuditEvents
+	Subscribers
+	NewsletterIssues
+	Webhooks
+	Outbox
 }
 
 func NewRepositories(db *sqlx.DB) *Repositories {
 	return &Repositories{
-		Users: newUserRepository(db),
+		TxManager:            newTxManager(db),
+		Users:                newUserRepository(db),
+		Media:                newMediaRepository(db),
+		Follows:              newFollowRepository(db),
+		Notifications:        newNotificationRepository(db),
+		NotificationSettings: newNotificationSettingRepository(db),
+		Reports:              newReportRepository(db),
+		PasswordResets:       newPasswordResetRepository(db),
+		AuditEvents:          newAuditRepository(db),
+		Subscribers:          newSubscriberRepository(db),
+		NewsletterIssues:     newNewsletterIssueRepository(db),
+		Webhooks:             newWebhookRepository(db),
+		Outbox:               newOutboxRepository(db),
 	}
 }
 
+type TxManager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 type Users interface {
 	Create(ctx context.Context, user *domain.User) error
 	GetByEmail(ctx context.Context, email string) (*domain.User, error)
+	GetByUsername(ctx context.Context, username string) (*domain.User, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.User, error)
+	UpdateStatus(ctx context.Context, user *domain.User) error
+	List(ctx context.Context, filter *domain.UserFilter) ([]domain.User, error)
+	UpdateRole(ctx context.Context, id uuid.UUID, role string) error
+	VerifyEmail(ctx context.Context, id uuid.UUID) error
+	RequirePasswordReset(ctx context.Context, id uuid.UUID) error
+	UpdatePassword(ctx context.Context, id uuid.UUID, password []byte) error
+}
+
+type Media interface {
+	Create(ctx context.Context, media *domain.Media) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Media, error)
+	ListProcessing(ctx context.Context) ([]domain.Media, error)
+	UpdateStatus(ctx context.Context, media *domain.Media) error
+}
+
+type Follows interface {
+	Create(ctx context.Context, follow *domain.Follow) (bool, error)
+	Delete(ctx context.Context, followerID, followeeID uuid.UUID) error
+	ListFollowers(ctx context.Context, userID uuid.UUID, cursor *domain.Cursor, limit int) ([]domain.FollowUser, error)
+	ListFollowing(ctx context.Context, userID uuid.UUID, cursor *domain.Cursor, limit int) ([]domain.FollowUser, error)
+}
+
+type Notifications interface {
+	Create(ctx context.Context, notification *domain.Notification) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Notification, error)
+	List(ctx context.Context, userID uuid.UUID, cursor *domain.Cursor, limit int) ([]domain.Notification, error)
+	ListAfter(ctx context.Context, userID, afterID uuid.UUID, limit int) ([]domain.Notification, error)
+	CountUnread(ctx context.Context, userID uuid.UUID) (int, error)
+	MarkRead(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) error
+	ClaimEmailPending(ctx context.Context, filter *domain.NotificationEmailFilter) ([]domain.NotificationEmail, error)
+	MarkEmailed(ctx context.Context, ids []uuid.UUID) error
+}
+
+type NotificationSettings interface {
+	List(ctx context.Context, userID uuid.UUID) ([]domain.NotificationSetting, error)
+	Upsert(ctx context.Context, settings []domain.NotificationSetting) error
+}
+
+type Reports interface {
+	Create(ctx context.Context, report *domain.Report) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Report, error)
+	List(ctx context.Context, filter *domain.ReportFilter) ([]domain.Report, error)
+	Resolve(ctx context.Context, report *domain.Report) error
+}
+
+type PasswordResets interface {
+	Create(ctx context.Context, reset *domain.PasswordReset) error
+	Use(ctx context.Context, tokenHash []byte) (*domain.PasswordReset, error)
+}
+
+type AuditEvents interface {
+	Create(ctx context.Context, event *domain.AuditEvent) error
+	List(ctx context.Context, filter *domain.AuditEventFilter) ([]domain.AuditEvent, error)
+}
+
+type Subscribers interface {
+	Subscribe(ctx context.Context, subscriber *domain.Subscriber) error
+	Confirm(ctx context.Context, tokenHash []byte) (*domain.Subscriber, error)
+	Unsubscribe(ctx context.Context, id uuid.UUID) error
+	Disable(ctx context.Context, email, status string) error
+}
+
+type NewsletterIssues interface {
+	Create(ctx context.Context, issue *domain.NewsletterIssue) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.NewsletterIssue, error)
+	List(ctx context.Context, cursor *domain.Cursor, limit int) ([]domain.NewsletterIssue, error)
+	StartSending(ctx context.Context, issue *domain.NewsletterIssue) error
+	ListSending(ctx context.Context) ([]domain.NewsletterIssue, error)
+	MarkSent(ctx context.Context, id uuid.UUID) error
+	ClaimRecipients(ctx context.Context, issueID uuid.UUID, limit int, lease time.Duration) ([]domain.Subscriber, error)
+	ReleaseRecipients(ctx context.Context, issueID uuid.UUID, subscriberIDs []uuid.UUID) error
+	CompleteDelivery(ctx context.Context, delivery *domain.NewsletterDelivery) error
+}
+
+type Webhooks interface {
+	CreateEndpoint(ctx context.Context, endpoint *domain.WebhookEndpoint) error
+	GetEndpoint(ctx context.Context, id uuid.UUID) (*domain.WebhookEndpoint, error)
+	ListEndpoints(ctx context.Context) ([]domain.WebhookEndpoint, error)
+	UpdateEndpoint(ctx context.Context, endpoint *domain.WebhookEndpoint) error
+	DeleteEndpoint(ctx context.Context, id uuid.UUID) error
+	CreateDeliveries(ctx context.Context, event string, payload []byte) (int, error)
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.WebhookDelivery, error)
+	SaveAttempt(ctx context.Context, delivery *domain.WebhookDelivery, attempt *domain.WebhookDeliveryAttempt, retryIn time.Duration) error
+	GetDelivery(ctx context.Context, id uuid.UUID) (*domain.WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, endpointID uuid.UUID, cursor *domain.Cursor, limit int) ([]domain.WebhookDelivery, error)
+	ListAttempts(ctx context.Context, deliveryID uuid.UUID) ([]domain.WebhookDeliveryAttempt, error)
+	Redeliver(ctx context.Context, delivery *domain.WebhookDelivery) error
+}
+
+type Outbox interface {
+	Create(ctx context.Context, event *domain.OutboxEvent) error
+	ClaimNext(ctx context.Context) (*domain.OutboxEvent, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	MarkFailed(ctx context.Context, id uuid.UUID, retryIn time.Duration, lastError string) error
+	Abandon(ctx context.Context, id uuid.UUID, lastError string) error
 }
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/newnorthblog/backend/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type subscriberRepository struct {
+	db *sqlx.DB
+}
+
+func newSubscriberRepository(db *sqlx.DB) *subscriberRepository {
+	return &subscriberRepository{
+		db: db,
+	}
+}
+
+// Subscribe adds a pending subscriber or gives a pending or unsubscribed one
+// a new confirmation token. It returns domain.ErrNoRowsAffected when the
+// address is already active or disabled, then subscriber.ID is not set.
+func (r *subscriberRepository) Subscribe(ctx context.Context, subscriber *domain.Subscriber) error {
+	const query = `
+	INSERT INTO subscriber
+	(id, email, status, confirm_token_hash, confirm_expires_at)
+	VALUES($1, $2, 'pending', $3, $4)
+	ON CONFLICT (email) DO UPDATE
+	SET status = 'pending',
+		confirm_token_hash = EXCLUDED.confirm_token_hash,
+		confirm_expires_at = EXCLUDED.confirm_expires_at,
+		updated_at = NOW()
+	WHERE subscriber.status IN ('pending', 'unsubscribed')
+	RETURNING id;
+	`
+
+	err := getExecutor(ctx, r.db).QueryRowxContext(ctx, query,
+		subscriber.ID, subscriber.Email, subscriber.ConfirmTokenHash, subscriber.ConfirmExpiresAt.UTC(),
+	).Scan(&subscriber.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.ErrNoRowsAffected
+		}
+		return fmt.Errorf("upsert subscriber failed: %w", err)
+	}
+
+	return nil
+}
+
+// Confirm activates the pending subscriber the unexpired token was issued to.
+func (r *subscriberRepository) Confirm(ctx context.Context, tokenHash []byte) (*domain.Subscriber, error) {
+	const query = `
+	UPDATE subscriber
+	SET status = 'active', confirmed_at = NOW(), confirm_token_hash = NULL, confirm_expires_at = NULL, updated_at = NOW()
+	WHERE confirm_token_hash = $1 AND status = 'pending' AND confirm_expires_at > NOW()
+	RETURNING id, email, status, confirm_token_hash, confirm_expires_at, confirmed_at, unsubscribed_at, disabled_at,
+		created_at, updated_at;
+	`
+
+	var subscriber domain.Subscriber
+	if err := getExecutor(ctx, r.db).GetContext(ctx, &subscriber, query, tokenHash); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("confirm subscriber failed: %w", err)
+	}
+
+	return &subscriber, nil
+}
+
+// Unsubscribe stops issues for the subscriber, disabled addresses keep their status.
+func (r *subscriberRepository) Unsubscribe(ctx context.Context, id uuid.UUID) error {
+	const query = `
+	UPDATE subscriber
+	SET status = 'unsubscribed', unsubscribed_at = NOW(), confirm_token_hash = NULL, updated_at = NOW()
+	WHERE id = $1 AND status IN ('pending', 'active');
+	`
+
+	if _, err := getExecutor(ctx, r.db).ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("unsubscribe subscriber failed: %w", err)
+	}
+
+	return nil
+}
+
+// Disable stops all mail to the address after a bounce or a complaint,
+// unknown addresses are ignored.
+func (r *subscriberRepository) Disable(ctx context.Context, email, status string) error {
+	const query = `
+	UPDATE subscriber
+	SET status = $2, disabled_at = NOW(), confirm_token_hash = NULL, updated_at = NOW()
+	WHERE email = $1;
+	`
+
+	if _, err := getExecutor(ctx, r.db).ExecContext(ctx, query, email, status); err != nil {
+		return fmt.Errorf("disable subscriber failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/newnorthblog/backend/internal/db"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	txMaxAttempts = 3
+	txRetryDelay  = 20 * time.Millisecond
+)
+
+type txKey struct{}
+
+// txState is the transaction in the context, depth counts the WithinTx calls
+// nested in the outermost one. retryErr is shared by all of them and keeps
+// the retryable error of a nested call, so that the transaction is run
+// again even if the caller of that call handled the error.
+type txState struct {
+	tx       *sqlx.Tx
+	depth    int
+	retryErr *error
+}
+
+// executor runs queries, it is the transaction from the context or the
+// database itself.
+type executor interface {
+	sqlx.ExtContext
+	GetContext(ctx context.Context, dest any, query string, args ...any) error
+	SelectContext(ctx context.Context, dest any, query string, args ...any) error
+}
+
+// getExecutor returns the transaction started by TxManager.WithinTx if ctx
+// carries one, so that repositories take part in it without knowing.
+func getExecutor(ctx context.Context, conn *sqlx.DB) executor {
+	if state, ok := ctx.Value(txKey{}).(txState); ok {
+		return state.tx
+	}
+
+	return conn
+}
+
+type txManager struct {
+	db *sqlx.DB
+}
+
+func newTxManager(db *sqlx.DB) *txManager {
+	return &txManager{
+		db: db,
+	}
+}
+
+// WithinTx runs fn in a transaction that repositories pick up from the
+// context passed to fn. The transaction is committed if fn returns nil and
+// rolled back otherwise.
+//
+// A call inside another WithinTx runs in a savepoint of the outer
+// transaction, its error rolls back only what fn did and the caller may go
+// on. A serialization failure or a deadlock is different: it is returned
+// unchanged, the savepoint is not rolled back and the outermost call rolls
+// back the whole transaction and runs its fn again, whatever the callers in
+// between did with the error. So fn must not have side effects outside the
+// database.
+func (m *txManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withinTx(ctx, m.db, fn)
+}
+
+// withinTx is WithinTx for repositories that need several statements to be
+// atomic on their own.
+func withinTx(ctx context.Context, conn *sqlx.DB, fn func(ctx context.Context) error) error {
+	if state, ok := ctx.Value(txKey{}).(txState); ok {
+		return withinSavepoint(ctx, state, fn)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := runTx(ctx, conn, fn)
+		if err == nil || attempt == txMaxAttempts || !db.IsRetryable(err) {
+			return err
+		}
+
+		// the jitter keeps the conflicting transactions from colliding again
+		delay := time.Duration(attempt)*txRetryDelay + rand.N(txRetryDelay)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+func runTx(ctx context.Context, conn *sqlx.DB, fn func(ctx context.Context) error) error {
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx failed: %w", err)
+	}
+	defer tx.Rollback()
+
+	var retryErr error
+	err = fn(context.WithValue(ctx, txKey{}, txState{tx: tx, retryErr: &retryErr}))
+	if retryErr != nil {
+		return retryErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx failed: %w", err)
+	}
+
+	return nil
+}
+
+func withinSavepoint(ctx context.Context, state txState, fn func(ctx context.Context) error) error {
+	// sibling calls reuse the name, each is released before the next one
+	state.depth++
+	savepoint := fmt.Sprintf("sp_%d", state.depth)
+
+	if _, err := state.tx.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+		return fmt.Errorf("create savepoint failed: %w", err)
+	}
+
+	if err := fn(context.WithValue(ctx, txKey{}, state)); err != nil {
+		// the transaction cannot succeed any more, it is left aborted so that
+		// nothing else commits and runTx retries it
+		if db.IsRetryable(err) {
+			*state.retryErr = err
+			return err
+		}
+		if _, rbErr := state.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+savepoint); rbErr != nil {
+			return fmt.Errorf("rollback to savepoint failed: %w (after %w)", rbErr, err)
+		}
+		return err
+	}
+
+	if _, err := state.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+savepoint); err != nil {
+		return fmt.Errorf("release savepoint failed: %w", err)
+	}
+
+	return nil
+}
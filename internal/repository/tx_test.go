@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/newnorthblog/backend/internal/db"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// fakeConn records the statements and transaction calls it gets instead of
+// running them.
+type fakeConn struct {
+	mu  sync.Mutex
+	log []string
+}
+
+func (c *fakeConn) record(s string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.log = append(c.log, s)
+}
+
+func (c *fakeConn) statements() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.log)
+}
+
+func (c *fakeConn) Connect(context.Context) (driver.Conn, error) { return c, nil }
+func (c *fakeConn) Driver() driver.Driver                        { return nil }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare is not supported")
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *fakeConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	c.record("BEGIN")
+	return fakeTx{c}, nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.record(query)
+	return driver.RowsAffected(0), nil
+}
+
+type fakeTx struct {
+	conn *fakeConn
+}
+
+func (tx fakeTx) Commit() error {
+	tx.conn.record("COMMIT")
+	return nil
+}
+
+func (tx fakeTx) Rollback() error {
+	tx.conn.record("ROLLBACK")
+	return nil
+}
+
+func newFakeDB(t *testing.T) (*sqlx.DB, *fakeConn) {
+	t.Helper()
+
+	conn := &fakeConn{}
+	sqlDB := sql.OpenDB(conn)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	return sqlx.NewDb(sqlDB, "postgres"), conn
+}
+
+func exec(ctx context.Context, conn *sqlx.DB, query string) error {
+	_, err := getExecutor(ctx, conn).ExecContext(ctx, query)
+	return err
+}
+
+var errSerialization = &pq.Error{Code: "40001", Message: "could not serialize access"}
+
+func TestWithinTxNestedFailureRollsBackToSavepoint(t *testing.T) {
+	conn, fake := newFakeDB(t)
+	errInner := errors.New("inner failed")
+
+	err := withinTx(context.Background(), conn, func(ctx context.Context) error {
+		if err := exec(ctx, conn, "INSERT a"); err != nil {
+			return err
+		}
+
+		err := withinTx(ctx, conn, func(ctx context.Context) error {
+			if err := exec(ctx, conn, "INSERT b"); err != nil {
+				return err
+			}
+			return errInner
+		})
+		if !errors.Is(err, errInner) {
+			t.Errorf("nested error = %v, want %v", err, errInner)
+		}
+
+		// a sibling reuses the name, its own nested call goes one level deeper
+		return withinTx(ctx, conn, func(ctx context.Context) error {
+			if err := exec(ctx, conn, "INSERT c"); err != nil {
+				return err
+			}
+			return withinTx(ctx, conn, func(ctx context.Context) error {
+				return exec(ctx, conn, "INSERT d")
+			})
+		})
+	})
+	if err != nil {
+		t.Fatalf("withinTx: %v", err)
+	}
+
+	want := []string{
+		"BEGIN",
+		"INSERT a",
+		"SAVEPOINT sp_1",
+		"INSERT b",
+		"ROLLBACK TO SAVEPOINT sp_1",
+		"SAVEPOINT sp_1",
+		"INSERT c",
+		"SAVEPOINT sp_2",
+		"INSERT d",
+		"RELEASE SAVEPOINT sp_2",
+		"RELEASE SAVEPOINT sp_1",
+		"COMMIT",
+	}
+	if got := fake.statements(); !slices.Equal(got, want) {
+		t.Errorf("statements:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestWithinTxRetriesRetryableErrors(t *testing.T) {
+	conn, fake := newFakeDB(t)
+
+	calls := 0
+	err := withinTx(context.Background(), conn, func(ctx context.Context) error {
+		calls++
+		return errSerialization
+	})
+	if !db.IsRetryable(err) {
+		t.Errorf("withinTx error = %v, want the serialization failure", err)
+	}
+	if calls != txMaxAttempts {
+		t.Errorf("fn ran %d times, want %d", calls, txMaxAttempts)
+	}
+	if slices.Contains(fake.statements(), "COMMIT") {
+		t.Error("a failed transaction was committed")
+	}
+}
+
+func TestWithinTxRetrySucceeds(t *testing.T) {
+	conn, fake := newFakeDB(t)
+
+	calls := 0
+	err := withinTx(context.Background(), conn, func(ctx context.Context) error {
+		calls++
+		if calls == 1 {
+			return errSerialization
+		}
+		return exec(ctx, conn, "INSERT a")
+	})
+	if err != nil {
+		t.Fatalf("withinTx: %v", err)
+	}
+
+	want := []string{"BEGIN", "ROLLBACK", "BEGIN", "INSERT a", "COMMIT"}
+	if got := fake.statements(); !slices.Equal(got, want) {
+		t.Errorf("statements = %q, want %q", got, want)
+	}
+}
+
+func TestWithinTxReturnsOtherErrorsAtOnce(t *testing.T) {
+	conn, fake := newFakeDB(t)
+	errFn := errors.New("failed")
+
+	calls := 0
+	err := withinTx(context.Background(), conn, func(ctx context.Context) error {
+		calls++
+		return errFn
+	})
+	if !errors.Is(err, errFn) {
+		t.Errorf("withinTx error = %v, want %v", err, errFn)
+	}
+	if calls != 1 {
+		t.Errorf("fn ran %d times, want 1", calls)
+	}
+
+	want := []string{"BEGIN", "ROLLBACK"}
+	if got := fake.statements(); !slices.Equal(got, want) {
+		t.Errorf("statements = %q, want %q", got, want)
+	}
+}
+
+// A nested serialization failure fails the whole transaction, even when the
+// caller of the nested call goes on as if it were an ordinary error.
+func TestWithinTxRetriesHandledNestedRetryableError(t *testing.T) {
+	conn, fake := newFakeDB(t)
+
+	calls := 0
+	err := withinTx(context.Background(), conn, func(ctx context.Context) error {
+		calls++
+		err := withinTx(ctx, conn, func(ctx context.Context) error {
+			if calls == 1 {
+				return errSerialization
+			}
+			return exec(ctx, conn, "INSERT a")
+		})
+		if err != nil && !db.IsRetryable(err) {
+			t.Errorf("nested error = %v, want it unchanged", err)
+		}
+
+		// the error is handled and the outer fn succeeds
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("withinTx: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("fn ran %d times, want 2", calls)
+	}
+
+	want := []string{
+		"BEGIN",
+		"SAVEPOINT sp_1",
+		"ROLLBACK",
+		"BEGIN",
+		"SAVEPOINT sp_1",
+		"INSERT a",
+		"RELEASE SAVEPOINT sp_1",
+		"COMMIT",
+	}
+	if got := fake.statements(); !slices.Equal(got, want) {
+		t.Errorf("statements:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/newnorthblog/backend/internal/db"
 	"github.com/newnorthblog/backend/internal/domain"
 
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 )
 
@@ -28,7 +31,7 @@ func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
 	VALUES($1, $2, $3, $4);
 	`
 
-	_, err := r.db.ExecContext(ctx, query, user.ID, user.Username, user.Email, user.Password)
+	_, err := getExecutor(ctx, r.db).ExecContext(ctx, query, user.ID, user.Username, user.Email, user.Password)
 	if err != nil {
 		if db.IsDuplicate(err) {
 			return domain.ErrDuplicateEntry
@@ -40,13 +43,14 @@ func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
 }
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
 	const query = `
-	SELECT id, username, email, "password", created_at, updated_at, deleted_at
+	SELECT id, username, email, "password", role, status, suspended_until, email_verified_at, password_reset_required,
+		created_at, updated_at, deleted_at
 	FROM "user"
 	WHERE email = $1;
 	`
 
 	var user domain.User
-	if err := r.db.GetContext(ctx, &user, query, email); err != nil {
+	if err := getExecutor(ctx, r.db).GetContext(ctx, &user, query, email); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, domain.ErrNotFound
 		}
@@ -55,3 +59,178 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*domain.
 
 	return &user, nil
 }
+
+func (r *userRepository) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
+	const query = `
+	SELECT id, username, email, "password", role, status, suspended_until, email_verified_at, password_reset_required,
+		created_at, updated_at, deleted_at
+	FROM "user"
+	WHERE username = $1 AND deleted_at IS NULL;
+	`
+
+	var user domain.User
+	if err := getExecutor(ctx, r.db).GetContext(ctx, &user, query, username); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("select user failed: %w", err)
+	}
+
+	return &user, nil
+}
+
+func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	const query = `
+	SELECT id, username, email, "password", role, status, suspended_until, email_verified_at, password_reset_required,
+		created_at, updated_at, deleted_at
+	FROM "user"
+	WHERE id = $1 AND deleted_at IS NULL;
+	`
+
+	var user domain.User
+	if err := getExecutor(ctx, r.db).GetContext(ctx, &user, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("select user failed: %w", err)
+	}
+
+	return &user, nil
+}
+
+func (r *userRepository) UpdateStatus(ctx context.Context, user *domain.User) error {
+	const query = `
+	UPDATE "user"
+	SET status = $2, suspended_until = $3, updated_at = NOW()
+	WHERE id = $1 AND deleted_at IS NULL;
+	`
+
+	res, err := getExecutor(ctx, r.db).ExecContext(ctx, query, user.ID, user.Status, user.SuspendedUntil)
+	if err != nil {
+		return fmt.Errorf("update user status failed: %w", err)
+	}
+
+	return checkAffected(res)
+}
+
+func (r *userRepository) List(ctx context.Context, filter *domain.UserFilter) ([]domain.User, error) {
+	const query = `
+	SELECT id, username, email, "password", role, status, suspended_until, email_verified_at, password_reset_required,
+		created_at, updated_at, deleted_at
+	FROM "user"
+	WHERE deleted_at IS NULL
+		AND ($1::text = '' OR email ILIKE '%' || $1 || '%' OR username ILIKE '%' || $1 || '%')
+		AND ($2::text = '' OR role = $2)
+		AND ($3::text = '' OR $3 = CASE
+			WHEN status = 'suspended' AND (suspended_until IS NULL OR suspended_until <= NOW()) THEN 'active'
+			ELSE status
+		END)
+		AND ($4::timestamp IS NULL OR created_at >= $4)
+		AND ($5::timestamp IS NULL OR created_at < $5)
+		AND ($6::timestamp IS NULL OR (created_at, id) < ($6, $7))
+	ORDER BY created_at DESC, id DESC
+	LIMIT $8;
+	`
+
+	var (
+		after   *time.Time
+		afterID uuid.UUID
+	)
+	if filter.Cursor != nil {
+		after = &filter.Cursor.CreatedAt
+		afterID = filter.Cursor.ID
+	}
+
+	users := make([]domain.User, 0, filter.Limit)
+	if err := getExecutor(ctx, r.db).SelectContext(ctx, &users, query,
+		escapeLike(filter.Search), filter.Role, filter.Status, filter.CreatedFrom, filter.CreatedTo,
+		after, afterID, filter.Limit,
+	); err != nil {
+		return nil, fmt.Errorf("select users failed: %w", err)
+	}
+
+	return users, nil
+}
+
+func (r *userRepository) UpdateRole(ctx context.Context, id uuid.UUID, role string) error {
+	const query = `
+	UPDATE "user"
+	SET role = $2, updated_at = NOW()
+	WHERE id = $1 AND deleted_at IS NULL;
+	`
+
+	res, err := getExecutor(ctx, r.db).ExecContext(ctx, query, id, role)
+	if err != nil {
+		return fmt.Errorf("update user role failed: %w", err)
+	}
+
+	return checkAffected(res)
+}
+
+// VerifyEmail marks the email verified, an already verified email keeps its
+// original time.
+func (r *userRepository) VerifyEmail(ctx context.Context, id uuid.UUID) error {
+	const query = `
+	UPDATE "user"
+	SET email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = NOW()
+	WHERE id = $1 AND deleted_at IS NULL;
+	`
+
+	res, err := getExecutor(ctx, r.db).ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("verify user email failed: %w", err)
+	}
+
+	return checkAffected(res)
+}
+
+func (r *userRepository) RequirePasswordReset(ctx context.Context, id uuid.UUID) error {
+	const query = `
+	UPDATE "user"
+	SET password_reset_required = TRUE, updated_at = NOW()
+	WHERE id = $1 AND deleted_at IS NULL;
+	`
+
+	res, err := getExecutor(ctx, r.db).ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("require user password reset failed: %w", err)
+	}
+
+	return checkAffected(res)
+}
+
+// UpdatePassword sets a new password and clears a pending forced reset.
+func (r *userRepository) UpdatePassword(ctx context.Context, id uuid.UUID, password []byte) error {
+	const query = `
+	UPDATE "user"
+	SET "password" = $2, password_reset_required = FALSE, updated_at = NOW()
+	WHERE id = $1 AND deleted_at IS NULL;
+	`
+
+	res, err := getExecutor(ctx, r.db).ExecContext(ctx, query, id, password)
+	if err != nil {
+		return fmt.Errorf("update user password failed: %w", err)
+	}
+
+	return checkAffected(res)
+}
+
+func checkAffected(res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected failed: %w", err)
+	}
+
+	if affected == 0 {
+		return domain.ErrNoRowsAffected
+	}
+
+	return nil
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike makes s match literally inside a LIKE pattern.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
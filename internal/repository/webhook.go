@@ -0,0 +1,301 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/newnorthblog/backend/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type webhookRepository struct {
+	db *sqlx.DB
+}
+
+func newWebhookRepository(db *sqlx.DB) *webhookRepository {
+	return &webhookRepository{
+		db: db,
+	}
+}
+
+// webhookEndpointRow scans the events array that domain.WebhookEndpoint
+// keeps as a plain slice.
+type webhookEndpointRow struct {
+	domain.WebhookEndpoint
+	Events pq.StringArray `db:"events"`
+}
+
+func (row *webhookEndpointRow) endpoint() *domain.WebhookEndpoint {
+	endpoint := row.WebhookEndpoint
+	endpoint.Events = row.Events
+	return &endpoint
+}
+
+func (r *webhookRepository) CreateEndpoint(ctx context.Context, endpoint *domain.WebhookEndpoint) error {
+	const query = `
+	INSERT INTO webhook_endpoint
+	(id, url, secret, events, active, created_by)
+	VALUES($1, $2, $3, $4, $5, $6)
+	RETURNING created_at, updated_at;
+	`
+
+	err := getExecutor(ctx, r.db).QueryRowxContext(ctx, query,
+		endpoint.ID, endpoint.URL, endpoint.Secret, pq.Array(endpoint.Events), endpoint.Active, endpoint.CreatedBy,
+	).Scan(&endpoint.CreatedAt, &endpoint.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("insert webhook endpoint failed: %w", err)
+	}
+
+	return nil
+}
+
+func (r *webhookRepository) GetEndpoint(ctx context.Context, id uuid.UUID) (*domain.WebhookEndpoint, error) {
+	const query = `
+	SELECT id, url, secret, events, active, created_by, created_at, updated_at
+	FROM webhook_endpoint
+	WHERE id = $1;
+	`
+
+	var row webhookEndpointRow
+	if err := getExecutor(ctx, r.db).GetContext(ctx, &row, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("select webhook endpoint failed: %w", err)
+	}
+
+	return row.endpoint(), nil
+}
+
+func (r *webhookRepository) ListEndpoints(ctx context.Context) ([]domain.WebhookEndpoint, error) {
+	const query = `
+	SELECT id, url, secret, events, active, created_by, created_at, updated_at
+	FROM webhook_endpoint
+	ORDER BY created_at;
+	`
+
+	var rows []webhookEndpointRow
+	if err := getExecutor(ctx, r.db).SelectContext(ctx, &rows, query); err != nil {
+		return nil, fmt.Errorf("select webhook endpoints failed: %w", err)
+	}
+
+	endpoints := make([]domain.WebhookEndpoint, 0, len(rows))
+	for i := range rows {
+		endpoints = append(endpoints, *rows[i].endpoint())
+	}
+
+	return endpoints, nil
+}
+
+func (r *webhookRepository) UpdateEndpoint(ctx context.Context, endpoint *domain.WebhookEndpoint) error {
+	const query = `
+	UPDATE webhook_endpoint
+	SET url = $2, events = $3, active = $4, updated_at = NOW()
+	WHERE id = $1
+	RETURNING secret, created_by, created_at, updated_at;
+	`
+
+	err := getExecutor(ctx, r.db).QueryRowxContext(ctx, query, endpoint.ID, endpoint.URL, pq.Array(endpoint.Events), endpoint.Active).
+		Scan(&endpoint.Secret, &endpoint.CreatedBy, &endpoint.CreatedAt, &endpoint.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.ErrNotFound
+		}
+		return fmt.Errorf("update webhook endpoint failed: %w", err)
+	}
+
+	return nil
+}
+
+func (r *webhookRepository) DeleteEndpoint(ctx context.Context, id uuid.UUID) error {
+	const query = `
+	DELETE FROM webhook_endpoint
+	WHERE id = $1;
+	`
+
+	res, err := getExecutor(ctx, r.db).ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("delete webhook endpoint failed: %w", err)
+	}
+
+	return checkAffected(res)
+}
+
+// CreateDeliveries queues the event for every active endpoint subscribed to
+// it and returns how many deliveries were queued.
+func (r *webhookRepository) CreateDeliveries(ctx context.Context, event string, payload []byte) (int, error) {
+	const query = `
+	INSERT INTO webhook_delivery
+	(id, endpoint_id, event, payload)
+	SELECT gen_random_uuid(), id, $1, $2
+	FROM webhook_endpoint
+	WHERE active AND $1 = ANY(events);
+	`
+
+	// payload goes as text, pq would send a []byte as bytea
+	res, err := getExecutor(ctx, r.db).ExecContext(ctx, query, event, string(payload))
+	if err != nil {
+		return 0, fmt.Errorf("insert webhook deliveries failed: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("get rows affected failed: %w", err)
+	}
+
+	return int(affected), nil
+}
+
+// ClaimDue returns up to limit pending deliveries that are due and moves
+// their next attempt lease into the future, so that other replicas skip them
+// while they are being sent.
+func (r *webhookRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	const query = `
+	UPDATE webhook_delivery
+	SET next_attempt_at = NOW() + make_interval(secs => $2)
+	WHERE id IN (
+		SELECT id FROM webhook_delivery
+		WHERE status = 'pending' AND next_attempt_at <= NOW()
+		ORDER BY next_attempt_at
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING id, endpoint_id, event, payload, status, attempts, next_attempt_at, last_attempt_at,
+		response_status, last_error, created_at;
+	`
+
+	var deliveries []domain.WebhookDelivery
+	if err := getExecutor(ctx, r.db).SelectContext(ctx, &deliveries, query, limit, lease.Seconds()); err != nil {
+		return nil, fmt.Errorf("claim webhook deliveries failed: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// SaveAttempt stores the outcome of an attempt on the delivery and adds it
+// to the delivery log. A pending delivery is retried after retryIn, the time
+// is taken from the database clock like in ClaimDue.
+func (r *webhookRepository) SaveAttempt(ctx context.Context, delivery *domain.WebhookDelivery, attempt *domain.WebhookDeliveryAttempt, retryIn time.Duration) error {
+	const deliveryQuery = `
+	UPDATE webhook_delivery
+	SET status = $2, attempts = $3, next_attempt_at = NOW() + make_interval(secs => $4), last_attempt_at = NOW(),
+		response_status = $5, last_error = $6
+	WHERE id = $1
+	RETURNING next_attempt_at, last_attempt_at;
+	`
+	const attemptQuery = `
+	INSERT INTO webhook_delivery_attempt
+	(id, delivery_id, response_status, error, duration_ms)
+	VALUES($1, $2, $3, $4, $5)
+	RETURNING created_at;
+	`
+
+	return withinTx(ctx, r.db, func(ctx context.Context) error {
+		tx := getExecutor(ctx, r.db)
+
+		err := tx.QueryRowxContext(ctx, deliveryQuery,
+			delivery.ID, delivery.Status, delivery.Attempts, retryIn.Seconds(), delivery.ResponseStatus, delivery.LastError,
+		).Scan(&delivery.NextAttemptAt, &delivery.LastAttemptAt)
+		if err != nil {
+			return fmt.Errorf("update webhook delivery failed: %w", err)
+		}
+
+		err = tx.QueryRowxContext(ctx, attemptQuery,
+			attempt.ID, attempt.DeliveryID, attempt.ResponseStatus, attempt.Error, attempt.DurationMS,
+		).Scan(&attempt.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("insert webhook delivery attempt failed: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *webhookRepository) GetDelivery(ctx context.Context, id uuid.UUID) (*domain.WebhookDelivery, error) {
+	const query = `
+	SELECT id, endpoint_id, event, payload, status, attempts, next_attempt_at, last_attempt_at,
+		response_status, last_error, created_at
+	FROM webhook_delivery
+	WHERE id = $1;
+	`
+
+	var delivery domain.WebhookDelivery
+	if err := getExecutor(ctx, r.db).GetContext(ctx, &delivery, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("select webhook delivery failed: %w", err)
+	}
+
+	return &delivery, nil
+}
+
+func (r *webhookRepository) ListDeliveries(ctx context.Context, endpointID uuid.UUID, cursor *domain.Cursor, limit int) ([]domain.WebhookDelivery, error) {
+	const query = `
+	SELECT id, endpoint_id, event, payload, status, attempts, next_attempt_at, last_attempt_at,
+		response_status, last_error, created_at
+	FROM webhook_delivery
+	WHERE endpoint_id = $1
+		AND ($2::timestamp IS NULL OR (created_at, id) < ($2, $3))
+	ORDER BY created_at DESC, id DESC
+	LIMIT $4;
+	`
+
+	var (
+		after   *time.Time
+		afterID uuid.UUID
+	)
+	if cursor != nil {
+		after = &cursor.CreatedAt
+		afterID = cursor.ID
+	}
+
+	deliveries := make([]domain.WebhookDelivery, 0, limit)
+	if err := getExecutor(ctx, r.db).SelectContext(ctx, &deliveries, query, endpointID, after, afterID, limit); err != nil {
+		return nil, fmt.Errorf("select webhook deliveries failed: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (r *webhookRepository) ListAttempts(ctx context.Context, deliveryID uuid.UUID) ([]domain.WebhookDeliveryAttempt, error) {
+	const query = `
+	SELECT id, delivery_id, response_status, error, duration_ms, created_at
+	FROM webhook_delivery_attempt
+	WHERE delivery_id = $1
+	ORDER BY created_at;
+	`
+
+	var attempts []domain.WebhookDeliveryAttempt
+	if err := getExecutor(ctx, r.db).SelectContext(ctx, &attempts, query, deliveryID); err != nil {
+		return nil, fmt.Errorf("select webhook delivery attempts failed: %w", err)
+	}
+
+	return attempts, nil
+}
+
+// Redeliver queues the delivery again right away with a fresh attempt budget,
+// the earlier attempts stay in the log.
+func (r *webhookRepository) Redeliver(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	const query = `
+	UPDATE webhook_delivery
+	SET status = 'pending', attempts = 0, next_attempt_at = NOW()
+	WHERE id = $1
+	RETURNING status, attempts, next_attempt_at;
+	`
+
+	err := getExecutor(ctx, r.db).QueryRowxContext(ctx, query, delivery.ID).Scan(&delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.ErrNotFound
+		}
+		return fmt.Errorf("redeliver webhook delivery failed: %w", err)
+	}
+
+	return nil
+}
@@ -27,6 +27,12 @@ func (s *Server) Run() error {
 	return s.httpServer.ListenAndServe()
 }
 
+// RegisterOnShutdown registers a function to call when the server starts shutting down,
+// it is used to end long lived connections that Stop would otherwise wait for.
+func (s *Server) RegisterOnShutdown(f func()) {
+	s.httpServer.RegisterOnShutdown(f)
+}
+
 func (s *Server) Stop(ctx context.Context) error {
 	return s.httpServer.Shutdown(ctx)
 }
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+	"unicode/utf8"
+
+	"github.com/newnorthblog/backend/internal/domain"
+	"github.com/newnorthblog/backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const auditExportBatchSize = 500
+
+type clientKey struct{}
+
+// Client describes where a request came from, handlers put it in the request
+// context so that services can write it to the audit log. ImpersonatorID is
+// set when an admin acts with an impersonation token.
+type Client struct {
+	IP             string
+	UserAgent      string
+	ImpersonatorID *uuid.UUID
+}
+
+func WithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// WithImpersonator marks the requests of an impersonation session, so that
+// the audit log keeps the admin behind the impersonated user.
+func WithImpersonator(ctx context.Context, adminID uuid.UUID) context.Context {
+	client := clientFromContext(ctx)
+	client.ImpersonatorID = &adminID
+	return WithClient(ctx, client)
+}
+
+func clientFromContext(ctx context.Context) Client {
+	client, _ := ctx.Value(clientKey{}).(Client)
+	return client
+}
+
+// AuditRecord is an action to write to the audit log, the client is taken
+// from the context.
+type AuditRecord struct {
+	Action     string
+	ActorID    *uuid.UUID
+	TargetType string
+	TargetID   *uuid.UUID
+	Details    map[string]any
+}
+
+// auditor is what other services use to record their actions.
+type auditor interface {
+	Record(ctx context.Context, record *AuditRecord)
+}
+
+type auditService struct {
+	auditRepository repository.AuditEvents
+	logger          *slog.Logger
+}
+
+func newAuditService(auditRepository repository.AuditEvents, logger *slog.Logger) *auditService {
+	return &auditService{
+		auditRepository: auditRepository,
+		logger:          logger,
+	}
+}
+
+// Record writes the action to the audit log. A failure does not fail the
+// action itself, the event goes to the application log instead.
+func (s *auditService) Record(ctx context.Context, record *AuditRecord) {
+	if err := s.record(ctx, record); err != nil {
+		s.logger.Error("failed to record audit event",
+			"action", record.Action,
+			"actor_id", record.ActorID,
+			"target_type", record.TargetType,
+			"target_id", record.TargetID,
+			"details", record.Details,
+			"error", err,
+		)
+	}
+}
+
+func (s *auditService) record(ctx context.Context, record *AuditRecord) error {
+	eventID, err := uuid.NewV7()
+	if err != nil {
+		return fmt.Errorf("generate audit event id failed: %w", err)
+	}
+
+	details := []byte("{}")
+	if len(record.Details) > 0 {
+		details, err = json.Marshal(record.Details)
+		if err != nil {
+			return fmt.Errorf("marshal audit details failed: %w", err)
+		}
+	}
+
+	client := clientFromContext(ctx)
+	return s.auditRepository.Create(ctx, &domain.AuditEvent{
+		ID:             eventID,
+		Action:         record.Action,
+		ActorID:        record.ActorID,
+		ImpersonatorID: client.ImpersonatorID,
+		IP:             client.IP,
+		UserAgent:      truncate(client.UserAgent, 512),
+		TargetType:     record.TargetType,
+		TargetID:       record.TargetID,
+		Details:        details,
+	})
+}
+
+type AuditFilterInput struct {
+	Action   string
+	ActorID  *uuid.UUID
+	TargetID *uuid.UUID
+	From     *time.Time
+	To       *time.Time
+}
+
+type AuditEventList struct {
+	Events     []domain.AuditEvent
+	NextCursor string
+}
+
+func (s *auditService) List(ctx context.Context, input *AuditFilterInput, cursor string, limit int) (*AuditEventList, error) {
+	after, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	limit = pageLimit(limit)
+	events, err := s.auditRepository.List(ctx, auditFilter(input, after, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("list audit events failed: %w", err)
+	}
+
+	list := &AuditEventList{Events: events}
+	if len(events) > limit {
+		list.Events = events[:limit]
+		last := list.Events[limit-1]
+		list.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+
+	return list, nil
+}
+
+// Export calls fn for every event matching the filter, newest first, reading
+// them in batches so that a large export does not sit in memory at once.
+func (s *auditService) Export(ctx context.Context, input *AuditFilterInput, fn func(event *domain.AuditEvent) error) error {
+	var after *domain.Cursor
+	for {
+		events, err := s.auditRepository.List(ctx, auditFilter(input, after, auditExportBatchSize))
+		if err != nil {
+			return fmt.Errorf("list audit events failed: %w", err)
+		}
+
+		for i := range events {
+			if err := fn(&events[i]); err != nil {
+				return err
+			}
+		}
+
+		if len(events) < auditExportBatchSize {
+			return nil
+		}
+
+		last := events[len(events)-1]
+		after = &domain.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+}
+
+func auditFilter(input *AuditFilterInput, after *domain.Cursor, limit int) *domain.AuditEventFilter {
+	return &domain.AuditEventFilter{
+		Action:   input.Action,
+		ActorID:  input.ActorID,
+		TargetID: input.TargetID,
+		From:     input.From,
+		To:       input.To,
+		Cursor:   after,
+		Limit:    limit,
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	// do not cut a multibyte character in half
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return s[:n]
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	htmlTemplate "html/template"
+	"log/slog"
+	"net/url"
+	"strings"
+	textTemplate "text/template"
+	"time"
+
+	"github.com/newnorthblog/backend/internal/config"
+	"github.com/newnorthblog/backend/internal/domain"
+	"github.com/newnorthblog/backend/internal/pkg/mailer"
+	"github.com/newnorthblog/backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const unsubscribePath = "/api/v1/notification-settings/unsubscribe"
+
+//go:embed templates/digest.*.tmpl
+var digestTemplates embed.FS
+
+var (
+	digestHTML = htmlTemplate.Must(htmlTemplate.ParseFS(digestTemplates, "templates/digest.html.tmpl"))
+	digestText = textTemplate.Must(textTemplate.ParseFS(digestTemplates, "templates/digest.txt.tmpl"))
+)
+
+var digestTitles = map[string]string{
+	domain.NotificationFrequencyImmediate: "Новые уведомления",
+	domain.NotificationFrequencyDaily:     "Уведомления за день",
+	domain.NotificationFrequencyWeekly:    "Уведомления за неделю",
+}
+
+type digestData struct {
+	Username       string
+	Title          string
+	Items          []string
+	UnsubscribeURL string
+}
+
+// digestService periodically emails pending notifications according to the
+// frequency each user chose for each notification kind.
+type digestService struct {
+	notificationRepository repository.Notifications
+	mailer                 mailer.Mailer
+	cfg                    *config.Config
+	logger                 *slog.Logger
+}
+
+func newDigestService(
+	notificationRepository repository.Notifications,
+	mailer mailer.Mailer,
+	cfg *config.Config,
+	logger *slog.Logger,
+) *digestService {
+	return &digestService{
+		notificationRepository: notificationRepository,
+		mailer:                 mailer,
+		cfg:                    cfg,
+		logger:                 logger,
+	}
+}
+
+// Run sends due digests every interval until ctx is done.
+func (s *digestService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Digest.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.send(ctx); err != nil {
+				s.logger.Error("failed to send notification digests", "error", err)
+			}
+		}
+	}
+}
+
+func (s *digestService) send(ctx context.Context) error {
+	// digests not sent before the claim runs out are left to the next check,
+	// another replica may claim them by then
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Digest.Lease)
+	defer cancel()
+
+	pending, err := s.notificationRepository.ClaimEmailPending(ctx, &domain.NotificationEmailFilter{
+		DefaultFrequency: s.cfg.Digest.DefaultFrequency,
+		Limit:            s.cfg.Digest.BatchSize,
+		Lease:            s.cfg.Digest.Lease,
+	})
+	if err != nil {
+		return fmt.Errorf("claim pending email notifications failed: %w", err)
+	}
+
+	// rows come ordered by recipient and frequency, each run of them is one digest
+	for start := 0; start < len(pending); {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		end := start + 1
+		for end < len(pending) &&
+			pending[end].UserID == pending[start].UserID &&
+			pending[end].Frequency == pending[start].Frequency {
+			end++
+		}
+
+		if err := s.sendDigest(ctx, pending[start:end]); err != nil {
+			s.logger.Error("failed to send notification digest",
+				"user_id", pending[start].UserID,
+				"error", err,
+			)
+		}
+		start = end
+	}
+
+	return nil
+}
+
+// sendDigest emails the unread notifications of one digest and marks all of
+// them handled, read ones and ones with emails off are skipped silently.
+func (s *digestService) sendDigest(ctx context.Context, notifications []domain.NotificationEmail) error {
+	recipient := notifications[0]
+
+	ids := make([]uuid.UUID, 0, len(notifications))
+	items := make([]string, 0, len(notifications))
+	for _, n := range notifications {
+		ids = append(ids, n.ID)
+		if n.ReadAt == nil && n.Frequency != domain.NotificationFrequencyOff {
+			items = append(items, describeNotification(&n.Notification))
+		}
+	}
+
+	if len(items) > 0 {
+		msg, err := s.buildDigest(&recipient, items)
+		if err != nil {
+			return err
+		}
+
+		if err := s.mailer.Send(ctx, msg); err != nil {
+			return fmt.Errorf("send digest failed: %w", err)
+		}
+	}
+
+	if err := s.notificationRepository.MarkEmailed(ctx, ids); err != nil {
+		return fmt.Errorf("mark notifications emailed failed: %w", err)
+	}
+
+	return nil
+}
+
+func (s *digestService) buildDigest(recipient *domain.NotificationEmail, items []string) (*mailer.Message, error) {
+	unsubscribeURL := strings.TrimRight(s.cfg.Mailer.BaseURL, "/") + unsubscribePath +
+		"?token=" + url.QueryEscape(unsubscribeToken(s.cfg.Mailer.UnsubscribeSecret, unsubscribeNotifications, recipient.UserID))
+
+	data := digestData{
+		Username:       recipient.Username,
+		Title:          digestTitles[recipient.Frequency],
+		Items:          items,
+		UnsubscribeURL: unsubscribeURL,
+	}
+
+	var text, html bytes.Buffer
+	if err := digestText.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("render digest text failed: %w", err)
+	}
+	if err := digestHTML.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("render digest html failed: %w", err)
+	}
+
+	return &mailer.Message{
+		To:      recipient.Email,
+		Subject: data.Title,
+		Text:    text.String(),
+		HTML:    html.String(),
+		Headers: map[string]string{
+			"List-Unsubscribe":      "<" + unsubscribeURL + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+	}, nil
+}
+
+func describeNotification(n *domain.Notification) string {
+	actor := "Кто-то"
+	if n.ActorUsername != nil {
+		actor = *n.ActorUsername
+	}
+
+	switch n.Kind {
+	case domain.NotificationKindFollow:
+		return actor + " подписался на вас"
+	}
+
+	return "Новое уведомление"
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/newnorthblog/backend/internal/config"
+	"github.com/newnorthblog/backend/internal/domain"
+	"github.com/newnorthblog/backend/internal/pkg/mailer"
+	"github.com/newnorthblog/backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// fakeNotifications serves the pending notifications once and records the
+// ones marked emailed.
+type fakeNotifications struct {
+	repository.Notifications
+	pending []domain.NotificationEmail
+	emailed []uuid.UUID
+}
+
+func (f *fakeNotifications) ClaimEmailPending(_ context.Context, _ *domain.NotificationEmailFilter) ([]domain.NotificationEmail, error) {
+	pending := f.pending
+	f.pending = nil
+	return pending, nil
+}
+
+func (f *fakeNotifications) MarkEmailed(_ context.Context, ids []uuid.UUID) error {
+	f.emailed = append(f.emailed, ids...)
+	return nil
+}
+
+func TestDigestRendersToFileMailer(t *testing.T) {
+	dir := t.TempDir()
+	fileMailer, err := mailer.NewFile(dir, "New North <noreply@example.com>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Mailer: config.Mailer{
+			BaseURL:           "https://blog.example.com/",
+			UnsubscribeSecret: "secret",
+		},
+		Digest: config.Digest{
+			DefaultFrequency: domain.NotificationFrequencyDaily,
+			BatchSize:        100,
+			Lease:            time.Minute,
+		},
+	}
+
+	alice, bob, carol := uuid.New(), uuid.New(), uuid.New()
+	follower := "<b>dave</b>"
+	readAt := time.Now()
+	pending := []domain.NotificationEmail{
+		notificationEmail(alice, "alice@example.com", "alice", domain.NotificationFrequencyDaily, &follower, nil),
+		notificationEmail(alice, "alice@example.com", "alice", domain.NotificationFrequencyDaily, nil, nil),
+		// read notifications and disabled emails are marked without a message
+		notificationEmail(bob, "bob@example.com", "bob", domain.NotificationFrequencyImmediate, &follower, &readAt),
+		notificationEmail(carol, "carol@example.com", "carol", domain.NotificationFrequencyOff, &follower, nil),
+	}
+	repo := &fakeNotifications{pending: pending}
+
+	s := newDigestService(repo, fileMailer, cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err := s.send(context.Background()); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	if len(repo.emailed) != len(pending) {
+		t.Errorf("marked %d notifications emailed, want %d", len(repo.emailed), len(pending))
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("got %d messages, want 1", len(files))
+	}
+
+	f, err := os.Open(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	msg, err := mail.ReadMessage(f)
+	if err != nil {
+		t.Fatalf("read message: %v", err)
+	}
+
+	if to := msg.Header.Get("To"); to != "alice@example.com" {
+		t.Errorf("To = %q, want alice@example.com", to)
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatalf("decode subject: %v", err)
+	}
+	if subject != "Уведомления за день" {
+		t.Errorf("Subject = %q", subject)
+	}
+
+	listUnsubscribe := msg.Header.Get("List-Unsubscribe")
+	prefix := "<https://blog.example.com" + unsubscribePath + "?token="
+	if !strings.HasPrefix(listUnsubscribe, prefix) || !strings.HasSuffix(listUnsubscribe, ">") {
+		t.Fatalf("List-Unsubscribe = %q", listUnsubscribe)
+	}
+	token := strings.TrimSuffix(strings.TrimPrefix(listUnsubscribe, prefix), ">")
+	if id, err := parseUnsubscribeToken("secret", unsubscribeNotifications, token); err != nil || id != alice {
+		t.Errorf("unsubscribe token is for %v (%v), want %v", id, err, alice)
+	}
+	if got := msg.Header.Get("List-Unsubscribe-Post"); got != "List-Unsubscribe=One-Click" {
+		t.Errorf("List-Unsubscribe-Post = %q", got)
+	}
+
+	parts := readParts(t, msg)
+
+	text := parts["text/plain"]
+	for _, want := range []string{
+		"Здравствуйте, alice!",
+		"- <b>dave</b> подписался на вас",
+		"- Кто-то подписался на вас",
+		"Отписаться от писем с уведомлениями: https://blog.example.com" + unsubscribePath,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text part has no %q:\n%s", want, text)
+		}
+	}
+
+	html := parts["text/html"]
+	if !strings.Contains(html, "&lt;b&gt;dave&lt;/b&gt; подписался на вас") {
+		t.Errorf("html part does not escape the actor name:\n%s", html)
+	}
+	if strings.Contains(html, "<b>dave</b>") {
+		t.Errorf("html part has the raw actor name:\n%s", html)
+	}
+}
+
+func notificationEmail(userID uuid.UUID, email, username, frequency string, actor *string, readAt *time.Time) domain.NotificationEmail {
+	return domain.NotificationEmail{
+		Notification: domain.Notification{
+			ID:            uuid.New(),
+			UserID:        userID,
+			ActorUsername: actor,
+			Kind:          domain.NotificationKindFollow,
+			ReadAt:        readAt,
+			CreatedAt:     time.Now(),
+		},
+		Email:     email,
+		Username:  username,
+		Frequency: frequency,
+	}
+}
+
+// readParts returns the decoded parts of a multipart message by media type.
+func readParts(t *testing.T, msg *mail.Message) map[string]string {
+	t.Helper()
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q (%v)", msg.Header.Get("Content-Type"), err)
+	}
+
+	parts := make(map[string]string)
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("read part: %v", err)
+		}
+
+		partType, _, err := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		if err != nil {
+			t.Fatalf("part Content-Type: %v", err)
+		}
+
+		// quoted-printable is decoded by the reader
+		data, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatalf("read part: %v", err)
+		}
+		parts[partType] = string(data)
+	}
+
+	return parts
+}
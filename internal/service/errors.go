@@ -6,4 +6,44 @@ var (
 	ErrUserAlreadyExists      = errors.New("user already exists")
 	ErrUserNotFound           = errors.New("user not found")
 	ErrUserInvalidCredentials = errors.New("invalid credentials")
+	ErrUserSuspended          = errors.New("user suspended")
+	ErrUserBanned             = errors.New("user banned")
+
+	ErrUserPasswordResetRequired = errors.New("user password reset required")
+	ErrInvalidPasswordResetToken = errors.New("invalid password reset token")
+	ErrChangeOwnRole             = errors.New("cannot change own role")
+	ErrImpersonateAdmin          = errors.New("cannot impersonate an admin")
+
+	ErrMediaTooLarge        = errors.New("media too large")
+	ErrMediaUnsupportedType = errors.New("unsupported media type")
+	ErrMediaNotFound        = errors.New("media not found")
+	ErrMediaQueueFull       = errors.New("media processing queue is full")
+
+	ErrFollowSelf = errors.New("cannot follow yourself")
+
+	ErrInvalidCursor = errors.New("invalid cursor")
+
+	ErrStreamClosed = errors.New("stream closed")
+
+	ErrUnknownNotificationKind      = errors.New("unknown notification kind")
+	ErrUnknownNotificationFrequency = errors.New("unknown notification frequency")
+	ErrInvalidUnsubscribeToken      = errors.New("invalid unsubscribe token")
+
+	ErrReportAlreadyExists     = errors.New("report already exists")
+	ErrReportNotFound          = errors.New("report not found")
+	ErrReportAlreadyResolved   = errors.New("report already resolved")
+	ErrReportUnsupportedTarget = errors.New("unsupported report target")
+	ErrReportSelf              = errors.New("cannot report yourself")
+	ErrModerateAdmin           = errors.New("cannot moderate an admin")
+
+	ErrInvalidSubscriptionToken   = errors.New("invalid subscription token")
+	ErrInvalidWebhookSecret       = errors.New("invalid webhook secret")
+	ErrUnknownNewsletterEvent     = errors.New("unknown newsletter event")
+	ErrNewsletterIssueNotFound    = errors.New("newsletter issue not found")
+	ErrNewsletterIssueAlreadySent = errors.New("newsletter issue already sent")
+
+	ErrUnknownWebhookEvent     = errors.New("unknown webhook event")
+	ErrInvalidWebhookURL       = errors.New("invalid webhook url")
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
 )
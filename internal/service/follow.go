@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/newnorthblog/backend/internal/domain"
+	"github.com/newnorthblog/backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type followService struct {
+	followRepository repository.Follows
+	userRepository   repository.Users
+	notifications    Notifications
+	logger           *slog.Logger
+}
+
+func newFollowService(
+	followRepository repository.Follows,
+	userRepository repository.Users,
+	notifications Notifications,
+	logger *slog.Logger,
+) *followService {
+	return &followService{
+		followRepository: followRepository,
+		userRepository:   userRepository,
+		notifications:    notifications,
+		logger:           logger,
+	}
+}
+
+func (s *followService) Follow(ctx context.Context, followerID uuid.UUID, username string) error {
+	author, err := s.getAuthor(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	if author.ID == followerID {
+		return ErrFollowSelf
+	}
+
+	created, err := s.followRepository.Create(ctx, &domain.Follow{
+		FollowerID: followerID,
+		FolloweeID: author.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("create follow failed: %w", err)
+	}
+
+	// repeated follows of the same author are not notified again
+	if created {
+		if err := s.notifications.Publish(ctx, &PublishNotificationInput{
+			UserID:  author.ID,
+			ActorID: &followerID,
+			Kind:    domain.NotificationKindFollow,
+		}); err != nil {
+			s.logger.Error("failed to publish follow notification",
+				"error", err,
+			)
+		}
+	}
+
+	return nil
+}
+
+func (s *followService) Unfollow(ctx context.Context, followerID uuid.UUID, username string) error {
+	author, err := s.getAuthor(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	if err := s.followRepository.Delete(ctx, followerID, author.ID); err != nil {
+		return fmt.Errorf("delete follow failed: %w", err)
+	}
+
+	return nil
+}
+
+type FollowList struct {
+	Users      []domain.FollowUser
+	NextCursor string
+}
+
+func (s *followService) Followers(ctx context.Context, username, cursor string, limit int) (*FollowList, error) {
+	return s.list(ctx, username, cursor, limit, s.followRepository.ListFollowers)
+}
+
+func (s *followService) Following(ctx context.Context, username, cursor string, limit int) (*FollowList, error) {
+	return s.list(ctx, username, cursor, limit, s.followRepository.ListFollowing)
+}
+
+type listFollowsFunc func(ctx context.Context, userID uuid.UUID, cursor *domain.Cursor, limit int) ([]domain.FollowUser, error)
+
+func (s *followService) list(ctx context.Context, username, cursor string, limit int, listFn listFollowsFunc) (*FollowList, error) {
+	after, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	author, err := s.getAuthor(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	limit = pageLimit(limit)
+	users, err := listFn(ctx, author.ID, after, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("list follows failed: %w", err)
+	}
+
+	list := &FollowList{Users: users}
+	if len(users) > limit {
+		list.Users = users[:limit]
+		last := list.Users[limit-1]
+		list.NextCursor = encodeCursor(last.FollowedAt, last.ID)
+	}
+
+	return list, nil
+}
+
+func (s *followService) getAuthor(ctx context.Context, username string) (*domain.User, error) {
+	author, err := s.userRepository.GetByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("get user by username failed: %w", err)
+	}
+
+	return author, nil
+}
@@ -0,0 +1,452 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/newnorthblog/backend/internal/config"
+	"github.com/newnorthblog/backend/internal/domain"
+	"github.com/newnorthblog/backend/internal/pkg/imageproc"
+	"github.com/newnorthblog/backend/internal/pkg/storage"
+	"github.com/newnorthblog/backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	mediaProcessTimeout = time.Minute
+
+	// mediaMaxNameLength is the length of the original_name column
+	mediaMaxNameLength = 255
+)
+
+var mediaExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// mediaOutputTypes maps uploaded types that are re-encoded to the stored type.
+// There is no pure Go WebP encoder, so WebP uploads are stored as JPEG. GIFs
+// stay GIFs to keep the animation and get no resized variants.
+var mediaOutputTypes = map[string]string{
+	"image/jpeg": "image/jpeg",
+	"image/png":  "image/png",
+	"image/gif":  "image/gif",
+	"image/webp": "image/jpeg",
+}
+
+// mediaJob is an image to process, data is nil for media queued again after
+// a restart, their original is read back from the storage.
+type mediaJob struct {
+	media *domain.Media
+	data  []byte
+}
+
+type mediaService struct {
+	mediaRepository repository.Media
+	storage         storage.Backend
+	cfg             config.Media
+	logger          *slog.Logger
+	jobs            chan mediaJob
+}
+
+func newMediaService(
+	mediaRepository repository.Media,
+	storage storage.Backend,
+	cfg config.Media,
+	logger *slog.Logger,
+) *mediaService {
+	widths := slices.Clone(cfg.Widths)
+	slices.Sort(widths)
+	cfg.Widths = slices.Compact(widths)
+
+	return &mediaService{
+		mediaRepository: mediaRepository,
+		storage:         storage,
+		cfg:             cfg,
+		logger:          logger,
+		jobs:            make(chan mediaJob, cfg.QueueSize),
+	}
+}
+
+type UploadMediaInput struct {
+	UserID       uuid.UUID
+	OriginalName string
+	Size         int64
+	File         io.Reader
+}
+
+// Upload validates the file and registers it. Images that need processing are
+// returned in the processing status with the URLs they will be available at,
+// the files themselves are written by the workers started with Run. The
+// original is kept in the storage until then, so that processing survives a
+// restart. ErrMediaQueueFull is returned when the workers are behind.
+func (s *mediaService) Upload(ctx context.Context, input *UploadMediaInput) (*domain.Media, error) {
+	if input.Size > s.cfg.MaxSize {
+		return nil, ErrMediaTooLarge
+	}
+
+	// the declared size may lie, so check what is actually read
+	data, err := io.ReadAll(io.LimitReader(input.File, s.cfg.MaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("read file failed: %w", err)
+	}
+
+	if int64(len(data)) > s.cfg.MaxSize {
+		return nil, ErrMediaTooLarge
+	}
+
+	// detect the type by magic bytes, the client supplied Content-Type is not trusted
+	mimeType := http.DetectContentType(data)
+	if _, ok := mediaExtensions[mimeType]; !ok || !slices.Contains(s.cfg.AllowedTypes, mimeType) {
+		return nil, ErrMediaUnsupportedType
+	}
+
+	mediaID, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("generate media id failed: %w", err)
+	}
+
+	media := &domain.Media{
+		ID:           mediaID,
+		UserID:       input.UserID,
+		OriginalName: sanitizeFileName(input.OriginalName),
+		MimeType:     mimeType,
+		Size:         int64(len(data)),
+		Status:       domain.MediaStatusReady,
+	}
+
+	outputType, process := mediaOutputTypes[mimeType]
+	if process {
+		if err := s.planVariants(media, data, outputType); err != nil {
+			return nil, err
+		}
+
+		if err := s.storeOriginal(ctx, media, data, mimeType); err != nil {
+			return nil, err
+		}
+	} else {
+		media.StorageKey = s.storageKey(media, "", mimeType)
+		if err := s.storage.Put(ctx, media.StorageKey, bytes.NewReader(data), mimeType); err != nil {
+			return nil, fmt.Errorf("store media failed: %w", err)
+		}
+	}
+
+	if err := s.mediaRepository.Create(ctx, media); err != nil {
+		if process {
+			s.deleteStored(ctx, *media.OriginalKey)
+		} else {
+			s.deleteStored(ctx, media.StorageKey)
+		}
+		return nil, fmt.Errorf("create media failed: %w", err)
+	}
+
+	if process {
+		// the worker gets its own copy, the returned media is read by the caller concurrently
+		jobMedia := *media
+		jobMedia.Variants = slices.Clone(media.Variants)
+
+		select {
+		case s.jobs <- mediaJob{media: &jobMedia, data: data}:
+		default:
+			ctx := context.WithoutCancel(ctx)
+			s.markFailed(ctx, media)
+			s.deleteStored(ctx, *jobMedia.OriginalKey)
+			return nil, ErrMediaQueueFull
+		}
+	}
+
+	s.resolveURLs(media)
+
+	return media, nil
+}
+
+// planVariants fills dimensions, storage keys and responsive variants of an
+// image that will be re-encoded by the workers.
+func (s *mediaService) planVariants(media *domain.Media, data []byte, outputType string) error {
+	width, height, err := imageproc.DecodeConfig(data)
+	if err != nil {
+		if errors.Is(err, imageproc.ErrTooManyPixels) {
+			return ErrMediaTooLarge
+		}
+		return ErrMediaUnsupportedType
+	}
+
+	media.Status = domain.MediaStatusProcessing
+	media.MimeType = outputType
+	media.Width = width
+	media.Height = height
+	media.StorageKey = s.storageKey(media, "", outputType)
+
+	if outputType == "image/gif" {
+		return nil
+	}
+
+	for _, w := range s.cfg.Widths {
+		if w >= width {
+			break
+		}
+
+		media.Variants = append(media.Variants, domain.MediaVariant{
+			MediaID:    media.ID,
+			Width:      w,
+			Height:     max(height*w/width, 1),
+			StorageKey: s.storageKey(media, fmt.Sprintf("-%dw", w), outputType),
+			MimeType:   outputType,
+		})
+	}
+
+	return nil
+}
+
+// storeOriginal keeps the uploaded image until it is processed. The key has a
+// random part, the original still has its metadata and must not be found by
+// the media id.
+func (s *mediaService) storeOriginal(ctx context.Context, media *domain.Media, data []byte, mimeType string) error {
+	secret, _, err := newSecretToken()
+	if err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf("media/originals/%s/%s-%s%s", media.UserID, media.ID, secret, mediaExtensions[mimeType])
+	if err := s.storage.Put(ctx, key, bytes.NewReader(data), mimeType); err != nil {
+		return fmt.Errorf("store original media failed: %w", err)
+	}
+	media.OriginalKey = &key
+
+	return nil
+}
+
+// Get returns the media uploaded by the user, so that the client can wait
+// for its processing to finish.
+func (s *mediaService) Get(ctx context.Context, userID, id uuid.UUID) (*domain.Media, error) {
+	media, err := s.mediaRepository.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrMediaNotFound
+		}
+		return nil, fmt.Errorf("get media failed: %w", err)
+	}
+
+	if media.UserID != userID {
+		return nil, ErrMediaNotFound
+	}
+
+	s.resolveURLs(media)
+
+	return media, nil
+}
+
+// sanitizeFileName keeps the base name of the client supplied file name
+// without control characters and invalid UTF-8, cut to the column length.
+func sanitizeFileName(name string) string {
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, strings.ToValidUTF8(name, ""))
+
+	if runes := []rune(name); len(runes) > mediaMaxNameLength {
+		name = string(runes[:mediaMaxNameLength])
+	}
+
+	return strings.TrimSpace(name)
+}
+
+func (s *mediaService) storageKey(media *domain.Media, suffix, mimeType string) string {
+	return fmt.Sprintf("media/%s/%s%s%s", media.UserID, media.ID, suffix, mediaExtensions[mimeType])
+}
+
+func (s *mediaService) resolveURLs(media *domain.Media) {
+	media.URL = s.storage.URL(media.StorageKey)
+	for i := range media.Variants {
+		media.Variants[i].URL = s.storage.URL(media.Variants[i].StorageKey)
+	}
+}
+
+// Run starts the image processing workers and blocks until ctx is done.
+// Media left in the processing status by an earlier run are queued again
+// from their stored originals. Another replica may be processing some of
+// them, the result is the same and only the first one finishes the media.
+func (s *mediaService) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for range max(s.cfg.Workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job := <-s.jobs:
+					s.process(context.WithoutCancel(ctx), job)
+				}
+			}
+		}()
+	}
+
+	s.requeue(ctx)
+	wg.Wait()
+}
+
+func (s *mediaService) requeue(ctx context.Context) {
+	media, err := s.mediaRepository.ListProcessing(ctx)
+	if err != nil {
+		s.logger.Error("failed to list processing media", "error", err)
+		return
+	}
+
+	for i := range media {
+		select {
+		case s.jobs <- mediaJob{media: &media[i]}:
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	if len(media) > 0 {
+		s.logger.Info("processing media queued again", "count", len(media))
+	}
+}
+
+// process strips metadata from the image by re-encoding it and writes its variants.
+func (s *mediaService) process(ctx context.Context, job mediaJob) {
+	ctx, cancel := context.WithTimeout(ctx, mediaProcessTimeout)
+	defer cancel()
+
+	media := job.media
+	// media uploaded before the originals were kept cannot be processed again
+	if media.OriginalKey == nil {
+		s.logger.Error("failed to process media",
+			"media_id", media.ID,
+			"error", "no original stored",
+		)
+		s.markFailed(ctx, media)
+		return
+	}
+
+	data := job.data
+	if data == nil {
+		var err error
+		data, err = s.storage.Get(ctx, *media.OriginalKey)
+		if err != nil {
+			if !errors.Is(err, storage.ErrNotFound) {
+				// left in the processing status for the next start
+				s.logger.Error("failed to read original media",
+					"media_id", media.ID,
+					"error", err,
+				)
+				return
+			}
+			// usually another replica has finished the media and deleted the
+			// original, markFailed does not touch media that are not processing
+			s.markFailed(ctx, media)
+			return
+		}
+	}
+	originalKey := *media.OriginalKey
+	defer s.deleteStored(ctx, originalKey)
+
+	size, err := s.writeImages(ctx, media, data)
+	if err != nil {
+		s.logger.Error("failed to process media",
+			"media_id", media.ID,
+			"error", err,
+		)
+		s.markFailed(ctx, media)
+		return
+	}
+
+	media.Status = domain.MediaStatusReady
+	media.Size = size
+	if err := s.mediaRepository.UpdateStatus(ctx, media); err != nil && !errors.Is(err, domain.ErrNoRowsAffected) {
+		s.logger.Error("failed to update media status",
+			"media_id", media.ID,
+			"error", err,
+		)
+	}
+}
+
+func (s *mediaService) writeImages(ctx context.Context, media *domain.Media, data []byte) (int64, error) {
+	if media.MimeType == "image/gif" {
+		return s.writeGIF(ctx, media, data)
+	}
+
+	img, err := imageproc.Decode(data)
+	if err != nil {
+		return 0, err
+	}
+
+	var buf bytes.Buffer
+	if err := imageproc.Encode(&buf, img, media.MimeType, s.cfg.JPEGQuality); err != nil {
+		return 0, fmt.Errorf("encode image failed: %w", err)
+	}
+	size := int64(buf.Len())
+
+	if err := s.storage.Put(ctx, media.StorageKey, &buf, media.MimeType); err != nil {
+		return 0, fmt.Errorf("store image failed: %w", err)
+	}
+
+	for _, v := range media.Variants {
+		buf.Reset()
+		if err := imageproc.Encode(&buf, imageproc.Resize(img, v.Width), v.MimeType, s.cfg.JPEGQuality); err != nil {
+			return 0, fmt.Errorf("encode %dw variant failed: %w", v.Width, err)
+		}
+
+		if err := s.storage.Put(ctx, v.StorageKey, &buf, v.MimeType); err != nil {
+			return 0, fmt.Errorf("store %dw variant failed: %w", v.Width, err)
+		}
+	}
+
+	return size, nil
+}
+
+func (s *mediaService) writeGIF(ctx context.Context, media *domain.Media, data []byte) (int64, error) {
+	var buf bytes.Buffer
+	if err := imageproc.ReencodeGIF(&buf, data); err != nil {
+		return 0, fmt.Errorf("encode gif failed: %w", err)
+	}
+	size := int64(buf.Len())
+
+	if err := s.storage.Put(ctx, media.StorageKey, &buf, media.MimeType); err != nil {
+		return 0, fmt.Errorf("store image failed: %w", err)
+	}
+
+	return size, nil
+}
+
+func (s *mediaService) markFailed(ctx context.Context, media *domain.Media) {
+	media.Status = domain.MediaStatusFailed
+	if err := s.mediaRepository.UpdateStatus(ctx, media); err != nil && !errors.Is(err, domain.ErrNoRowsAffected) {
+		s.logger.Error("failed to mark media as failed",
+			"media_id", media.ID,
+			"error", err,
+		)
+	}
+}
+
+func (s *mediaService) deleteStored(ctx context.Context, key string) {
+	if err := s.storage.Delete(ctx, key); err != nil {
+		s.logger.Error("failed to delete stored media",
+			"key", key,
+			"error", err,
+		)
+	}
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/newnorthblog/backend/internal/domain"
+	"github.com/newnorthblog/backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type moderationService struct {
+	reportRepository repository.Reports
+	userRepository   repository.Users
+	audit            auditor
+	logger           *slog.Logger
+}
+
+func newModerationService(
+	reportRepository repository.Reports,
+	userRepository repository.Users,
+	audit auditor,
+	logger *slog.Logger,
+) *moderationService {
+	return &moderationService{
+		reportRepository: reportRepository,
+		userRepository:   userRepository,
+		audit:            audit,
+		logger:           logger,
+	}
+}
+
+type ReportInput struct {
+	ReporterID uuid.UUID
+	TargetType string
+	TargetID   uuid.UUID
+	Reason     string
+}
+
+// Report files a report about abusive content or a user. Only users can be
+// reported for now, there is no other content to report yet.
+func (s *moderationService) Report(ctx context.Context, input *ReportInput) (*domain.Report, error) {
+	if input.TargetType != domain.ReportTargetUser {
+		return nil, ErrReportUnsupportedTarget
+	}
+
+	if input.TargetID == input.ReporterID {
+		return nil, ErrReportSelf
+	}
+
+	if _, err := s.getUser(ctx, input.TargetID); err != nil {
+		return nil, err
+	}
+
+	reportID, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("generate report id failed: %w", err)
+	}
+
+	report := &domain.Report{
+		ID:         reportID,
+		ReporterID: input.ReporterID,
+		TargetType: input.TargetType,
+		TargetID:   input.TargetID,
+		Reason:     input.Reason,
+		Status:     domain.ReportStatusOpen,
+	}
+	if err := s.reportRepository.Create(ctx, report); err != nil {
+		if errors.Is(err, domain.ErrDuplicateEntry) {
+			return nil, ErrReportAlreadyExists
+		}
+		return nil, fmt.Errorf("create report failed: %w", err)
+	}
+
+	return report, nil
+}
+
+type ReportList struct {
+	Reports    []domain.Report
+	NextCursor string
+}
+
+func (s *moderationService) ListReports(ctx context.Context, status, cursor string, limit int) (*ReportList, error) {
+	after, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	limit = pageLimit(limit)
+	reports, err := s.reportRepository.List(ctx, &domain.ReportFilter{
+		Status: status,
+		Cursor: after,
+		Limit:  limit + 1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list reports failed: %w", err)
+	}
+
+	list := &ReportList{Reports: reports}
+	if len(reports) > limit {
+		list.Reports = reports[:limit]
+		last := list.Reports[limit-1]
+		list.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+
+	return list, nil
+}
+
+// ResolveReport closes an open report as resolved or dismissed.
+func (s *moderationService) ResolveReport(ctx context.Context, adminID, reportID uuid.UUID, status string) (*domain.Report, error) {
+	report, err := s.reportRepository.GetByID(ctx, reportID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrReportNotFound
+		}
+		return nil, fmt.Errorf("get report failed: %w", err)
+	}
+
+	report.Status = status
+	report.ResolvedBy = &adminID
+	if err := s.reportRepository.Resolve(ctx, report); err != nil {
+		if errors.Is(err, domain.ErrNoRowsAffected) {
+			return nil, ErrReportAlreadyResolved
+		}
+		return nil, fmt.Errorf("resolve report failed: %w", err)
+	}
+
+	s.audit.Record(ctx, &AuditRecord{
+		Action:     domain.AuditActionReportResolve,
+		ActorID:    &adminID,
+		TargetType: domain.AuditTargetReport,
+		TargetID:   &reportID,
+		Details:    map[string]any{"status": status},
+	})
+
+	return report, nil
+}
+
+// SuspendUser blocks the user until the suspension is over.
+func (s *moderationService) SuspendUser(ctx context.Context, adminID, userID uuid.UUID, duration time.Duration) (*domain.User, error) {
+	until := time.Now().UTC().Add(duration)
+
+	return s.setUserStatus(ctx, adminID, userID, domain.AuditActionUserSuspend, domain.UserStatusSuspended, &until)
+}
+
+// BanUser blocks the user permanently.
+func (s *moderationService) BanUser(ctx context.Context, adminID, userID uuid.UUID) (*domain.User, error) {
+	return s.setUserStatus(ctx, adminID, userID, domain.AuditActionUserBan, domain.UserStatusBanned, nil)
+}
+
+// ReinstateUser lifts a suspension or a ban.
+func (s *moderationService) ReinstateUser(ctx context.Context, adminID, userID uuid.UUID) (*domain.User, error) {
+	return s.setUserStatus(ctx, adminID, userID, domain.AuditActionUserReinstate, domain.UserStatusActive, nil)
+}
+
+func (s *moderationService) setUserStatus(
+	ctx context.Context,
+	adminID, userID uuid.UUID,
+	action, status string,
+	suspendedUntil *time.Time,
+) (*domain.User, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Role == domain.UserRoleAdmin {
+		return nil, ErrModerateAdmin
+	}
+
+	oldStatus := user.Status
+	user.Status = status
+	user.SuspendedUntil = suspendedUntil
+	if err := s.userRepository.UpdateStatus(ctx, user); err != nil {
+		return nil, fmt.Errorf("update user status failed: %w", err)
+	}
+
+	details := map[string]any{"old_status": oldStatus, "status": status}
+	if suspendedUntil != nil {
+		details["suspended_until"] = suspendedUntil
+	}
+	s.audit.Record(ctx, &AuditRecord{
+		Action:     action,
+		ActorID:    &adminID,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   &userID,
+		Details:    details,
+	})
+
+	return user, nil
+}
+
+func (s *moderationService) getUser(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	user, err := s.userRepository.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("get user by id failed: %w", err)
+	}
+
+	return user, nil
+}
@@ -0,0 +1,455 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"embed"
+	"errors"
+	"fmt"
+	htmlTemplate "html/template"
+	"log/slog"
+	"net/url"
+	"strings"
+	textTemplate "text/template"
+	"time"
+
+	"github.com/newnorthblog/backend/internal/config"
+	"github.com/newnorthblog/backend/internal/domain"
+	"github.com/newnorthblog/backend/internal/pkg/mailer"
+	"github.com/newnorthblog/backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	newsletterConfirmPath     = "/api/v1/newsletter/confirm"
+	newsletterUnsubscribePath = "/api/v1/newsletter/unsubscribe"
+
+	newsletterConfirmSubject = "Подтверждение подписки на рассылку"
+)
+
+const (
+	NewsletterEventBounce    = "bounce"
+	NewsletterEventComplaint = "complaint"
+)
+
+//go:embed templates/newsletter_*.tmpl
+var newsletterTemplates embed.FS
+
+var (
+	newsletterConfirmHTML = htmlTemplate.Must(htmlTemplate.ParseFS(newsletterTemplates, "templates/newsletter_confirm.html.tmpl"))
+	newsletterConfirmText = textTemplate.Must(textTemplate.ParseFS(newsletterTemplates, "templates/newsletter_confirm.txt.tmpl"))
+	newsletterIssueHTML   = htmlTemplate.Must(htmlTemplate.ParseFS(newsletterTemplates, "templates/newsletter_issue.html.tmpl"))
+	newsletterIssueText   = textTemplate.Must(textTemplate.ParseFS(newsletterTemplates, "templates/newsletter_issue.txt.tmpl"))
+)
+
+type newsletterConfirmData struct {
+	ConfirmURL string
+}
+
+type newsletterIssueData struct {
+	Subject        string
+	Text           string
+	HTML           htmlTemplate.HTML
+	UnsubscribeURL string
+}
+
+// newsletterService manages subscriptions of readers without accounts and
+// sends newsletter issues to confirmed subscribers in batches.
+type newsletterService struct {
+	subscriberRepository repository.Subscribers
+	issueRepository      repository.NewsletterIssues
+	mailer               mailer.Mailer
+	audit                auditor
+	cfg                  *config.Config
+	logger               *slog.Logger
+}
+
+func newNewsletterService(
+	subscriberRepository repository.Subscribers,
+	issueRepository repository.NewsletterIssues,
+	mailer mailer.Mailer,
+	audit auditor,
+	cfg *config.Config,
+	logger *slog.Logger,
+) *newsletterService {
+	return &newsletterService{
+		subscriberRepository: subscriberRepository,
+		issueRepository:      issueRepository,
+		mailer:               mailer,
+		audit:                audit,
+		cfg:                  cfg,
+		logger:               logger,
+	}
+}
+
+// Subscribe emails a confirmation link to the address. Addresses that are
+// already subscribed or disabled get nothing and the caller cannot tell them
+// apart, so that the endpoint does not reveal who is subscribed.
+func (s *newsletterService) Subscribe(ctx context.Context, email string) error {
+	subscriberID, err := uuid.NewV7()
+	if err != nil {
+		return fmt.Errorf("generate subscriber id failed: %w", err)
+	}
+
+	token, tokenHash, err := newSecretToken()
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(s.cfg.Newsletter.ConfirmTTL)
+	subscriber := &domain.Subscriber{
+		ID:               subscriberID,
+		Email:            normalizeEmail(email),
+		ConfirmTokenHash: tokenHash,
+		ConfirmExpiresAt: &expiresAt,
+	}
+	if err := s.subscriberRepository.Subscribe(ctx, subscriber); err != nil {
+		if errors.Is(err, domain.ErrNoRowsAffected) {
+			return nil
+		}
+		return fmt.Errorf("subscribe failed: %w", err)
+	}
+
+	msg, err := s.buildConfirmMessage(subscriber.Email, token)
+	if err != nil {
+		return err
+	}
+
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		return fmt.Errorf("send subscription confirmation failed: %w", err)
+	}
+
+	return nil
+}
+
+func (s *newsletterService) Confirm(ctx context.Context, token string) error {
+	if _, err := s.subscriberRepository.Confirm(ctx, hashSecretToken(token)); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return ErrInvalidSubscriptionToken
+		}
+		return fmt.Errorf("confirm subscriber failed: %w", err)
+	}
+
+	return nil
+}
+
+func (s *newsletterService) Unsubscribe(ctx context.Context, token string) error {
+	subscriberID, err := parseUnsubscribeToken(s.cfg.Mailer.UnsubscribeSecret, unsubscribeNewsletter, token)
+	if err != nil {
+		return err
+	}
+
+	if err := s.subscriberRepository.Unsubscribe(ctx, subscriberID); err != nil {
+		return fmt.Errorf("unsubscribe failed: %w", err)
+	}
+
+	return nil
+}
+
+type NewsletterEventInput struct {
+	Type      string
+	Email     string
+	Permanent bool
+}
+
+// HandleEvent applies a bounce or a complaint reported by the mail provider.
+// Complaints and permanent bounces disable the address, temporary bounces
+// are left to the provider's retries. Events are rejected while no secret
+// is configured.
+func (s *newsletterService) HandleEvent(ctx context.Context, secret string, input *NewsletterEventInput) error {
+	if s.cfg.Newsletter.WebhookSecret == "" || !hmac.Equal([]byte(secret), []byte(s.cfg.Newsletter.WebhookSecret)) {
+		return ErrInvalidWebhookSecret
+	}
+
+	var status string
+	switch input.Type {
+	case NewsletterEventBounce:
+		if !input.Permanent {
+			return nil
+		}
+		status = domain.SubscriberStatusBounced
+	case NewsletterEventComplaint:
+		status = domain.SubscriberStatusComplained
+	default:
+		return ErrUnknownNewsletterEvent
+	}
+
+	email := normalizeEmail(input.Email)
+	if err := s.subscriberRepository.Disable(ctx, email, status); err != nil {
+		return fmt.Errorf("disable subscriber failed: %w", err)
+	}
+
+	s.logger.Info("newsletter subscriber disabled",
+		"email", email,
+		"status", status,
+	)
+
+	return nil
+}
+
+type NewsletterIssueInput struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// CreateIssue saves a draft issue with content written by the admin.
+func (s *newsletterService) CreateIssue(ctx context.Context, adminID uuid.UUID, input *NewsletterIssueInput) (*domain.NewsletterIssue, error) {
+	issueID, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("generate newsletter issue id failed: %w", err)
+	}
+
+	issue := &domain.NewsletterIssue{
+		ID:        issueID,
+		Subject:   input.Subject,
+		Text:      input.Text,
+		HTML:      input.HTML,
+		Status:    domain.NewsletterIssueStatusDraft,
+		CreatedBy: &adminID,
+	}
+	if err := s.issueRepository.Create(ctx, issue); err != nil {
+		return nil, fmt.Errorf("create newsletter issue failed: %w", err)
+	}
+
+	s.audit.Record(ctx, &AuditRecord{
+		Action:     domain.AuditActionNewsletterIssueCreate,
+		ActorID:    &adminID,
+		TargetType: domain.AuditTargetIssue,
+		TargetID:   &issueID,
+		Details:    map[string]any{"subject": issue.Subject},
+	})
+
+	return issue, nil
+}
+
+type NewsletterIssueList struct {
+	Issues     []domain.NewsletterIssue
+	NextCursor string
+}
+
+func (s *newsletterService) ListIssues(ctx context.Context, cursor string, limit int) (*NewsletterIssueList, error) {
+	after, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	limit = pageLimit(limit)
+	issues, err := s.issueRepository.List(ctx, after, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("list newsletter issues failed: %w", err)
+	}
+
+	list := &NewsletterIssueList{Issues: issues}
+	if len(issues) > limit {
+		list.Issues = issues[:limit]
+		last := list.Issues[limit-1]
+		list.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+
+	return list, nil
+}
+
+// SendIssue queues a draft for sending, the worker delivers it in batches.
+func (s *newsletterService) SendIssue(ctx context.Context, adminID, issueID uuid.UUID) (*domain.NewsletterIssue, error) {
+	issue, err := s.issueRepository.GetByID(ctx, issueID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrNewsletterIssueNotFound
+		}
+		return nil, fmt.Errorf("get newsletter issue failed: %w", err)
+	}
+
+	if err := s.issueRepository.StartSending(ctx, issue); err != nil {
+		if errors.Is(err, domain.ErrNoRowsAffected) {
+			return nil, ErrNewsletterIssueAlreadySent
+		}
+		return nil, fmt.Errorf("start sending newsletter issue failed: %w", err)
+	}
+
+	s.audit.Record(ctx, &AuditRecord{
+		Action:     domain.AuditActionNewsletterIssueSend,
+		ActorID:    &adminID,
+		TargetType: domain.AuditTargetIssue,
+		TargetID:   &issueID,
+	})
+
+	return issue, nil
+}
+
+// Run sends the next batch of every issue being sent each interval until ctx is done.
+func (s *newsletterService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Newsletter.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.send(ctx); err != nil {
+				s.logger.Error("failed to send newsletter issues", "error", err)
+			}
+		}
+	}
+}
+
+func (s *newsletterService) send(ctx context.Context) error {
+	issues, err := s.issueRepository.ListSending(ctx)
+	if err != nil {
+		return fmt.Errorf("list sending newsletter issues failed: %w", err)
+	}
+
+	for i := range issues {
+		if err := s.sendBatch(ctx, &issues[i]); err != nil {
+			s.logger.Error("failed to send newsletter batch",
+				"issue_id", issues[i].ID,
+				"error", err,
+			)
+		}
+	}
+
+	return nil
+}
+
+// sendBatch claims the next batch of subscribers that have not got the
+// issue yet, sends it to them and marks the issue sent once nobody is left.
+// A message the mailer rejects is recorded with its error and not retried.
+// Claims left by a replica that stopped mid-batch run out after the lease
+// and are claimed again.
+func (s *newsletterService) sendBatch(ctx context.Context, issue *domain.NewsletterIssue) error {
+	// subscribers not sent to before the claim runs out are released, another
+	// replica may claim them by then
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Newsletter.Lease)
+	defer cancel()
+
+	batchSize := s.cfg.Newsletter.BatchSize
+	subscribers, err := s.issueRepository.ClaimRecipients(ctx, issue.ID, batchSize, s.cfg.Newsletter.Lease)
+	if err != nil {
+		return fmt.Errorf("claim newsletter recipients failed: %w", err)
+	}
+
+	// subscribers the batch did not get to, on shutdown or an error, are
+	// released so that they get the issue on a later run
+	sent := 0
+	defer func() {
+		if sent == len(subscribers) {
+			return
+		}
+
+		ids := make([]uuid.UUID, 0, len(subscribers)-sent)
+		for _, subscriber := range subscribers[sent:] {
+			ids = append(ids, subscriber.ID)
+		}
+		if err := s.issueRepository.ReleaseRecipients(context.WithoutCancel(ctx), issue.ID, ids); err != nil {
+			s.logger.Error("failed to release newsletter recipients",
+				"issue_id", issue.ID,
+				"error", err,
+			)
+		}
+	}()
+
+	for i := range subscribers {
+		subscriber := &subscribers[i]
+		delivery := &domain.NewsletterDelivery{
+			IssueID:      issue.ID,
+			SubscriberID: subscriber.ID,
+			Status:       domain.NewsletterDeliveryStatusSent,
+		}
+
+		msg, err := s.buildIssueMessage(issue, subscriber)
+		if err != nil {
+			return err
+		}
+
+		if err := s.mailer.Send(ctx, msg); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			errText := err.Error()
+			delivery.Status = domain.NewsletterDeliveryStatusFailed
+			delivery.Error = &errText
+			s.logger.Error("failed to send newsletter issue",
+				"issue_id", issue.ID,
+				"subscriber_id", subscriber.ID,
+				"error", err,
+			)
+		}
+		sent++
+
+		if err := s.issueRepository.CompleteDelivery(ctx, delivery); err != nil {
+			return fmt.Errorf("complete newsletter delivery failed: %w", err)
+		}
+	}
+
+	if len(subscribers) < batchSize {
+		if err := s.issueRepository.MarkSent(ctx, issue.ID); err != nil {
+			return fmt.Errorf("mark newsletter issue sent failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *newsletterService) buildConfirmMessage(email, token string) (*mailer.Message, error) {
+	data := newsletterConfirmData{
+		ConfirmURL: s.link(newsletterConfirmPath, token),
+	}
+
+	var text, html bytes.Buffer
+	if err := newsletterConfirmText.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("render subscription confirmation text failed: %w", err)
+	}
+	if err := newsletterConfirmHTML.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("render subscription confirmation html failed: %w", err)
+	}
+
+	return &mailer.Message{
+		To:      email,
+		Subject: newsletterConfirmSubject,
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+func (s *newsletterService) buildIssueMessage(issue *domain.NewsletterIssue, subscriber *domain.Subscriber) (*mailer.Message, error) {
+	unsubscribeURL := s.link(newsletterUnsubscribePath,
+		unsubscribeToken(s.cfg.Mailer.UnsubscribeSecret, unsubscribeNewsletter, subscriber.ID))
+
+	// the issue HTML is written by an admin and goes out as is
+	data := newsletterIssueData{
+		Subject:        issue.Subject,
+		Text:           issue.Text,
+		HTML:           htmlTemplate.HTML(issue.HTML),
+		UnsubscribeURL: unsubscribeURL,
+	}
+
+	var text, html bytes.Buffer
+	if err := newsletterIssueText.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("render newsletter issue text failed: %w", err)
+	}
+	if err := newsletterIssueHTML.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("render newsletter issue html failed: %w", err)
+	}
+
+	return &mailer.Message{
+		To:      subscriber.Email,
+		Subject: issue.Subject,
+		Text:    text.String(),
+		HTML:    html.String(),
+		Headers: map[string]string{
+			"List-Unsubscribe":      "<" + unsubscribeURL + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+	}, nil
+}
+
+func (s *newsletterService) link(path, token string) string {
+	return strings.TrimRight(s.cfg.Mailer.BaseURL, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/newnorthblog/backend/internal/domain"
+	"github.com/newnorthblog/backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type notificationService struct {
+	notificationRepository repository.Notifications
+	logger                 *slog.Logger
+}
+
+func newNotificationService(
+	notificationRepository repository.Notifications,
+	logger *slog.Logger,
+) *notificationService {
+	return &notificationService{
+		notificationRepository: notificationRepository,
+		logger:                 logger,
+	}
+}
+
+type PublishNotificationInput struct {
+	UserID   uuid.UUID
+	ActorID  *uuid.UUID
+	Kind     string
+	TargetID *uuid.UUID
+}
+
+// Publish creates a notification for the user. Actions of users on their own
+// content are not notified.
+func (s *notificationService) Publish(ctx context.Context, input *PublishNotificationInput) error {
+	if input.ActorID != nil && *input.ActorID == input.UserID {
+		return nil
+	}
+
+	notificationID, err := uuid.NewV7()
+	if err != nil {
+		return fmt.Errorf("generate notification id failed: %w", err)
+	}
+
+	if err := s.notificationRepository.Create(ctx, &domain.Notification{
+		ID:       notificationID,
+		UserID:   input.UserID,
+		ActorID:  input.ActorID,
+		Kind:     input.Kind,
+		TargetID: input.TargetID,
+	}); err != nil {
+		return fmt.Errorf("create notification failed: %w", err)
+	}
+
+	return nil
+}
+
+type NotificationList struct {
+	Notifications []domain.Notification
+	UnreadCount   int
+	NextCursor    string
+}
+
+func (s *notificationService) List(ctx context.Context, userID uuid.UUID, cursor string, limit int) (*NotificationList, error) {
+	after, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	limit = pageLimit(limit)
+	notifications, err := s.notificationRepository.List(ctx, userID, after, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("list notifications failed: %w", err)
+	}
+
+	unread, err := s.notificationRepository.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("count unread notifications failed: %w", err)
+	}
+
+	list := &NotificationList{
+		Notifications: notifications,
+		UnreadCount:   unread,
+	}
+	if len(notifications) > limit {
+		list.Notifications = notifications[:limit]
+		last := list.Notifications[limit-1]
+		list.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+
+	return list, nil
+}
+
+// MarkRead marks the given notifications as read, all of them when ids is empty.
+func (s *notificationService) MarkRead(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) error {
+	if err := s.notificationRepository.MarkRead(ctx, userID, ids); err != nil {
+		return fmt.Errorf("mark notifications read failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/newnorthblog/backend/internal/config"
+	"github.com/newnorthblog/backend/internal/domain"
+	"github.com/newnorthblog/backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+var notificationFrequencies = []string{
+	domain.NotificationFrequencyImmediate,
+	domain.NotificationFrequencyDaily,
+	domain.NotificationFrequencyWeekly,
+	domain.NotificationFrequencyOff,
+}
+
+type notificationSettingService struct {
+	settingRepository repository.NotificationSettings
+	cfg               *config.Config
+	logger            *slog.Logger
+}
+
+func newNotificationSettingService(
+	settingRepository repository.NotificationSettings,
+	cfg *config.Config,
+	logger *slog.Logger,
+) *notificationSettingService {
+	return &notificationSettingService{
+		settingRepository: settingRepository,
+		cfg:               cfg,
+		logger:            logger,
+	}
+}
+
+// Get returns settings for every notification kind, kinds the user has not
+// configured get the default frequency.
+func (s *notificationSettingService) Get(ctx context.Context, userID uuid.UUID) ([]domain.NotificationSetting, error) {
+	stored, err := s.settingRepository.List(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list notification settings failed: %w", err)
+	}
+
+	settings := make([]domain.NotificationSetting, 0, len(domain.NotificationKinds))
+	for _, kind := range domain.NotificationKinds {
+		setting := domain.NotificationSetting{
+			UserID:    userID,
+			Kind:      kind,
+			Frequency: s.cfg.Digest.DefaultFrequency,
+		}
+
+		if i := slices.IndexFunc(stored, func(st domain.NotificationSetting) bool { return st.Kind == kind }); i >= 0 {
+			setting.Frequency = stored[i].Frequency
+		}
+
+		settings = append(settings, setting)
+	}
+
+	return settings, nil
+}
+
+func (s *notificationSettingService) Update(ctx context.Context, userID uuid.UUID, settings []domain.NotificationSetting) error {
+	for i := range settings {
+		if !slices.Contains(domain.NotificationKinds, settings[i].Kind) {
+			return ErrUnknownNotificationKind
+		}
+
+		if !slices.Contains(notificationFrequencies, settings[i].Frequency) {
+			return ErrUnknownNotificationFrequency
+		}
+
+		settings[i].UserID = userID
+	}
+
+	if err := s.settingRepository.Upsert(ctx, settings); err != nil {
+		return fmt.Errorf("upsert notification settings failed: %w", err)
+	}
+
+	return nil
+}
+
+// Unsubscribe turns off emails of all kinds for the user the token was issued to.
+func (s *notificationSettingService) Unsubscribe(ctx context.Context, token string) error {
+	userID, err := parseUnsubscribeToken(s.cfg.Mailer.UnsubscribeSecret, unsubscribeNotifications, token)
+	if err != nil {
+		return err
+	}
+
+	settings := make([]domain.NotificationSetting, 0, len(domain.NotificationKinds))
+	for _, kind := range domain.NotificationKinds {
+		settings = append(settings, domain.NotificationSetting{
+			UserID:    userID,
+			Kind:      kind,
+			Frequency: domain.NotificationFrequencyOff,
+		})
+	}
+
+	if err := s.settingRepository.Upsert(ctx, settings); err != nil {
+		return fmt.Errorf("upsert notification settings failed: %w", err)
+	}
+
+	return nil
+}
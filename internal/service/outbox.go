@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/newnorthblog/backend/internal/config"
+	"github.com/newnorthblog/backend/internal/domain"
+	"github.com/newnorthblog/backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// eventPublisher is what other services use to publish domain events. An
+// event published inside TxManager.WithinTx is written in that transaction
+// and only relayed if it commits.
+type eventPublisher interface {
+	Publish(ctx context.Context, event string, data any) error
+}
+
+// eventHandler handles a relayed event. It runs in the relay transaction, so
+// what it writes through repositories is committed together with removing
+// the event from the outbox. Other side effects may repeat if the relay
+// fails afterwards and must be idempotent.
+type eventHandler func(ctx context.Context, event *domain.OutboxEvent) error
+
+// outboxService relays events from the outbox table to in-process handlers.
+type outboxService struct {
+	outboxRepository repository.Outbox
+	txManager        repository.TxManager
+	handlers         map[string][]eventHandler
+	cfg              config.Outbox
+	logger           *slog.Logger
+}
+
+func newOutboxService(
+	outboxRepository repository.Outbox,
+	txManager repository.TxManager,
+	cfg config.Outbox,
+	logger *slog.Logger,
+) *outboxService {
+	return &outboxService{
+		outboxRepository: outboxRepository,
+		txManager:        txManager,
+		handlers:         make(map[string][]eventHandler),
+		cfg:              cfg,
+		logger:           logger,
+	}
+}
+
+// Subscribe adds a handler for the event, it must be called before Run.
+func (s *outboxService) Subscribe(event string, handler eventHandler) {
+	s.handlers[event] = append(s.handlers[event], handler)
+}
+
+func (s *outboxService) Publish(ctx context.Context, event string, data any) error {
+	eventID, err := uuid.NewV7()
+	if err != nil {
+		return fmt.Errorf("generate outbox event id failed: %w", err)
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("marshal outbox event failed: %w", err)
+	}
+
+	if err := s.outboxRepository.Create(ctx, &domain.OutboxEvent{
+		ID:      eventID,
+		Event:   event,
+		Payload: payload,
+	}); err != nil {
+		return fmt.Errorf("create outbox event failed: %w", err)
+	}
+
+	return nil
+}
+
+// Run relays due events every interval until ctx is done.
+func (s *outboxService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.relay(ctx); err != nil {
+				s.logger.Error("failed to relay outbox events", "error", err)
+			}
+		}
+	}
+}
+
+func (s *outboxService) relay(ctx context.Context) error {
+	for range s.cfg.BatchSize {
+		relayed, err := s.relayNext(ctx)
+		if err != nil {
+			return err
+		}
+		if !relayed {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// relayNext hands the next due event to its handlers and removes it, each
+// event gets its own transaction so that a failing one does not hold back
+// the rest. An event that fails MaxAttempts times is left failed and not
+// retried. It returns false when no event is due.
+func (s *outboxService) relayNext(ctx context.Context) (bool, error) {
+	var (
+		event     *domain.OutboxEvent
+		handleErr error
+	)
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		event, err = s.outboxRepository.ClaimNext(ctx)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				return nil
+			}
+			return fmt.Errorf("claim outbox event failed: %w", err)
+		}
+
+		if handleErr = s.dispatch(ctx, event); handleErr != nil {
+			return handleErr
+		}
+
+		return s.outboxRepository.Delete(ctx, event.ID)
+	})
+	if handleErr != nil {
+		if ctx.Err() != nil {
+			// shutting down, the event is relayed on the next start
+			return false, nil
+		}
+
+		// the transaction is rolled back, so the failure is saved outside of it
+		attempts := event.Attempts + 1
+		if attempts >= s.cfg.MaxAttempts {
+			if err := s.outboxRepository.Abandon(ctx, event.ID, handleErr.Error()); err != nil {
+				return false, fmt.Errorf("save outbox event failure failed: %w", err)
+			}
+			s.logger.Error("outbox event dropped after the last attempt",
+				"event_id", event.ID,
+				"event", event.Event,
+				"payload", string(event.Payload),
+				"attempts", attempts,
+				"error", handleErr,
+			)
+			return true, nil
+		}
+
+		if err := s.outboxRepository.MarkFailed(ctx, event.ID,
+			backoff(s.cfg.BackoffBase, s.cfg.BackoffMax, attempts), handleErr.Error(),
+		); err != nil {
+			return false, fmt.Errorf("save outbox event failure failed: %w", err)
+		}
+		s.logger.Error("failed to handle outbox event",
+			"event_id", event.ID,
+			"event", event.Event,
+			"attempts", attempts,
+			"error", handleErr,
+		)
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return event != nil, nil
+}
+
+func (s *outboxService) dispatch(ctx context.Context, event *domain.OutboxEvent) error {
+	for _, handler := range s.handlers[event.Event] {
+		if err := handler(ctx, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// backoff doubles the delay after every failed attempt up to the maximum.
+func backoff(base, maximum time.Duration, attempts int) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < maximum; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maximum)
+}
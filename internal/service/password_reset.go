@@ -0,0 +1,52 @@
+package service
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmlTemplate "html/template"
+	textTemplate "text/template"
+	"time"
+
+	"github.com/newnorthblog/backend/internal/domain"
+	"github.com/newnorthblog/backend/internal/pkg/mailer"
+)
+
+const passwordResetSubject = "Сброс пароля"
+
+//go:embed templates/password_reset.*.tmpl
+var passwordResetTemplates embed.FS
+
+var (
+	passwordResetHTML = htmlTemplate.Must(htmlTemplate.ParseFS(passwordResetTemplates, "templates/password_reset.html.tmpl"))
+	passwordResetText = textTemplate.Must(textTemplate.ParseFS(passwordResetTemplates, "templates/password_reset.txt.tmpl"))
+)
+
+type passwordResetData struct {
+	Username  string
+	Token     string
+	ExpiresAt string
+}
+
+func buildPasswordResetMessage(user *domain.User, token string, expiresAt time.Time) (*mailer.Message, error) {
+	data := passwordResetData{
+		Username:  user.Username,
+		Token:     token,
+		ExpiresAt: expiresAt.UTC().Format("02.01.2006 15:04 UTC"),
+	}
+
+	var text, html bytes.Buffer
+	if err := passwordResetText.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("render password reset text failed: %w", err)
+	}
+	if err := passwordResetHTML.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("render password reset html failed: %w", err)
+	}
+
+	return &mailer.Message{
+		To:      user.Email,
+		Subject: passwordResetSubject,
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
@@ -3,14 +3,34 @@ package service
 import (
 	"context"
 	"log/slog"
+	"time"
 
 	"github.com/newnorthblog/backend/internal/config"
+	"github.com/newnorthblog/backend/internal/domain"
+	"github.com/newnorthblog/backend/internal/pkg/mailer"
+	"github.com/newnorthblog/backend/internal/pkg/storage"
 	"github.com/newnorthblog/backend/internal/pkg/tokenmanager"
 	"github.com/newnorthblog/backend/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type Services struct {
 	Users
+	Media
+	Follows
+	Notifications
+	Stream
+	NotificationSettings
+	Moderation
+	UserAdmin
+	Audit
+	Newsletter
+	Webhooks
+
+	// Workers are background processes started by main and stopped on shutdown.
+	Workers []Worker
 }
 
 type Deps struct {
@@ -18,15 +38,119 @@ type Deps struct {
 	Config       *config.Config
 	Repos        *repository.Repositories
 	TokenManager *tokenmanager.Manager
+	Storage      storage.Backend
+	Listener     *pq.Listener
+	Mailer       mailer.Mailer
 }
 
 func NewServices(deps Deps) *Services {
+	mediaService := newMediaService(deps.Repos.Media, deps.Storage, deps.Config.Media, deps.Logger)
+	notificationService := newNotificationService(deps.Repos.Notifications, deps.Logger)
+	streamService := newStreamService(deps.Listener, deps.Repos.Notifications, deps.Config.Stream, deps.Logger)
+	auditService := newAuditService(deps.Repos.AuditEvents, deps.Logger)
+	digestService := newDigestService(deps.Repos.Notifications, deps.Mailer, deps.Config, deps.Logger)
+	newsletterService := newNewsletterService(deps.Repos.Subscribers, deps.Repos.NewsletterIssues, deps.Mailer, auditService, deps.Config, deps.Logger)
+	webhookService := newWebhookService(deps.Repos.Webhooks, auditService, deps.Config.Webhook, deps.Logger)
+	outboxService := newOutboxService(deps.Repos.Outbox, deps.Repos.TxManager, deps.Config.Outbox, deps.Logger)
+	for _, event := range domain.WebhookEvents {
+		outboxService.Subscribe(event, webhookService.HandleEvent)
+	}
+
 	return &Services{
-		Users: newUserService(deps.Repos.Users, deps.Logger, deps.TokenManager),
+		Users:                newUserService(deps.Repos.Users, deps.Repos.PasswordResets, deps.Repos.TxManager, auditService, outboxService, deps.Logger, deps.TokenManager),
+		Media:                mediaService,
+		Follows:              newFollowService(deps.Repos.Follows, deps.Repos.Users, notificationService, deps.Logger),
+		Notifications:        notificationService,
+		Stream:               streamService,
+		NotificationSettings: newNotificationSettingService(deps.Repos.NotificationSettings, deps.Config, deps.Logger),
+		Moderation:           newModerationService(deps.Repos.Reports, deps.Repos.Users, auditService, deps.Logger),
+		UserAdmin:            newUserAdminService(deps.Repos.Users, deps.Repos.PasswordResets, deps.Repos.TxManager, auditService, deps.TokenManager, deps.Mailer, deps.Config, deps.Logger),
+		Audit:                auditService,
+		Newsletter:           newsletterService,
+		Webhooks:             webhookService,
+		Workers:              []Worker{mediaService, streamService, digestService, newsletterService, webhookService, outboxService},
 	}
 }
 
 type Users interface {
 	Register(ctx context.Context, input *RegisterInput) error
 	Login(ctx context.Context, email, password string) (*Tokens, error)
+	GetActive(ctx context.Context, id uuid.UUID) (*domain.User, error)
+	ResetPassword(ctx context.Context, token, password string) error
+}
+
+type Media interface {
+	Upload(ctx context.Context, input *UploadMediaInput) (*domain.Media, error)
+	Get(ctx context.Context, userID, id uuid.UUID) (*domain.Media, error)
+}
+
+type Follows interface {
+	Follow(ctx context.Context, followerID uuid.UUID, username string) error
+	Unfollow(ctx context.Context, followerID uuid.UUID, username string) error
+	Followers(ctx context.Context, username, cursor string, limit int) (*FollowList, error)
+	Following(ctx context.Context, username, cursor string, limit int) (*FollowList, error)
+}
+
+type Notifications interface {
+	Publish(ctx context.Context, input *PublishNotificationInput) error
+	List(ctx context.Context, userID uuid.UUID, cursor string, limit int) (*NotificationList, error)
+	MarkRead(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) error
+}
+
+type Stream interface {
+	Subscribe(ctx context.Context, userID uuid.UUID, lastEventID string) (*Subscription, error)
+	Shutdown()
+}
+
+type NotificationSettings interface {
+	Get(ctx context.Context, userID uuid.UUID) ([]domain.NotificationSetting, error)
+	Update(ctx context.Context, userID uuid.UUID, settings []domain.NotificationSetting) error
+	Unsubscribe(ctx context.Context, token string) error
+}
+
+type Moderation interface {
+	Report(ctx context.Context, input *ReportInput) (*domain.Report, error)
+	ListReports(ctx context.Context, status, cursor string, limit int) (*ReportList, error)
+	ResolveReport(ctx context.Context, adminID, reportID uuid.UUID, status string) (*domain.Report, error)
+	SuspendUser(ctx context.Context, adminID, userID uuid.UUID, duration time.Duration) (*domain.User, error)
+	BanUser(ctx context.Context, adminID, userID uuid.UUID) (*domain.User, error)
+	ReinstateUser(ctx context.Context, adminID, userID uuid.UUID) (*domain.User, error)
+}
+
+type UserAdmin interface {
+	ListUsers(ctx context.Context, input *ListUsersInput) (*UserList, error)
+	ChangeRole(ctx context.Context, adminID, userID uuid.UUID, role string) (*domain.User, error)
+	PromoteAdmin(ctx context.Context, email string) error
+	ForcePasswordReset(ctx context.Context, adminID, userID uuid.UUID) (emailSent bool, err error)
+	VerifyEmail(ctx context.Context, adminID, userID uuid.UUID) (*domain.User, error)
+	Impersonate(ctx context.Context, adminID, userID uuid.UUID) (*ImpersonationToken, error)
+}
+
+type Audit interface {
+	List(ctx context.Context, input *AuditFilterInput, cursor string, limit int) (*AuditEventList, error)
+	Export(ctx context.Context, input *AuditFilterInput, fn func(event *domain.AuditEvent) error) error
+}
+
+type Newsletter interface {
+	Subscribe(ctx context.Context, email string) error
+	Confirm(ctx context.Context, token string) error
+	Unsubscribe(ctx context.Context, token string) error
+	HandleEvent(ctx context.Context, secret string, input *NewsletterEventInput) error
+	CreateIssue(ctx context.Context, adminID uuid.UUID, input *NewsletterIssueInput) (*domain.NewsletterIssue, error)
+	ListIssues(ctx context.Context, cursor string, limit int) (*NewsletterIssueList, error)
+	SendIssue(ctx context.Context, adminID, issueID uuid.UUID) (*domain.NewsletterIssue, error)
+}
+
+type Webhooks interface {
+	CreateEndpoint(ctx context.Context, adminID uuid.UUID, input *WebhookEndpointInput) (*domain.WebhookEndpoint, error)
+	ListEndpoints(ctx context.Context) ([]domain.WebhookEndpoint, error)
+	UpdateEndpoint(ctx context.Context, adminID, endpointID uuid.UUID, input *WebhookEndpointInput) (*domain.WebhookEndpoint, error)
+	DeleteEndpoint(ctx context.Context, adminID, endpointID uuid.UUID) error
+	ListDeliveries(ctx context.Context, endpointID uuid.UUID, cursor string, limit int) (*WebhookDeliveryList, error)
+	GetDelivery(ctx context.Context, deliveryID uuid.UUID) (*domain.WebhookDelivery, []domain.WebhookDeliveryAttempt, error)
+	Redeliver(ctx context.Context, adminID, deliveryID uuid.UUID) (*domain.WebhookDelivery, error)
+}
+
+type Worker interface {
+	Run(ctx context.Context)
 }
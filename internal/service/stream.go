@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/newnorthblog/backend/internal/config"
+	"github.com/newnorthblog/backend/internal/domain"
+	"github.com/newnorthblog/backend/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	// notificationChannel is notified by a trigger on the notification table.
+	notificationChannel = "notification"
+
+	streamBufferSize  = 16
+	streamLoadTimeout = 5 * time.Second
+)
+
+const (
+	StreamEventNotification = "notification"
+	StreamEventPing         = "ping"
+)
+
+type StreamEvent struct {
+	Kind         string
+	Notification *domain.Notification
+}
+
+// Subscription receives stream events of a single user. Events is closed when
+// the subscriber falls behind or the stream shuts down, the client is expected
+// to reconnect and resume from its last event.
+type Subscription struct {
+	Backlog []StreamEvent
+	Events  <-chan StreamEvent
+
+	close func()
+}
+
+func (s *Subscription) Close() {
+	s.close()
+}
+
+type streamSubscriber struct {
+	userID uuid.UUID
+	events chan StreamEvent
+}
+
+// streamService fans out events to SSE subscribers of this replica. Events are
+// received through Postgres LISTEN/NOTIFY, so they reach subscribers connected
+// to any replica.
+type streamService struct {
+	listener               *pq.Listener
+	notificationRepository repository.Notifications
+	cfg                    config.Stream
+	logger                 *slog.Logger
+
+	mu          sync.Mutex
+	closed      bool
+	subscribers map[uuid.UUID]map[*streamSubscriber]struct{}
+}
+
+func newStreamService(
+	listener *pq.Listener,
+	notificationRepository repository.Notifications,
+	cfg config.Stream,
+	logger *slog.Logger,
+) *streamService {
+	return &streamService{
+		listener:               listener,
+		notificationRepository: notificationRepository,
+		cfg:                    cfg,
+		logger:                 logger,
+		subscribers:            make(map[uuid.UUID]map[*streamSubscriber]struct{}),
+	}
+}
+
+// Subscribe registers a subscriber for the user. When lastEventID is set, the
+// notifications created after it are returned in the backlog. Live events may
+// repeat backlog items and should be deduplicated by the caller.
+func (s *streamService) Subscribe(ctx context.Context, userID uuid.UUID, lastEventID string) (*Subscription, error) {
+	sub := &streamSubscriber{
+		userID: userID,
+		events: make(chan StreamEvent, streamBufferSize),
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil, ErrStreamClosed
+	}
+	if s.subscribers[userID] == nil {
+		s.subscribers[userID] = make(map[*streamSubscriber]struct{})
+	}
+	s.subscribers[userID][sub] = struct{}{}
+	s.mu.Unlock()
+
+	subscription := &Subscription{
+		Events: sub.events,
+		close:  func() { s.unsubscribe(sub) },
+	}
+
+	// subscribe before loading the backlog so nothing is missed in between
+	if lastID, err := uuid.Parse(lastEventID); err == nil {
+		notifications, err := s.notificationRepository.ListAfter(ctx, userID, lastID, s.cfg.ReplayLimit)
+		if err != nil {
+			subscription.Close()
+			return nil, fmt.Errorf("list notifications after last event failed: %w", err)
+		}
+
+		for i := range notifications {
+			subscription.Backlog = append(subscription.Backlog, StreamEvent{
+				Kind:         StreamEventNotification,
+				Notification: &notifications[i],
+			})
+		}
+	}
+
+	return subscription, nil
+}
+
+// unsubscribe removes the subscriber and closes its channel. It is safe to call
+// for a subscriber that was already removed.
+func (s *streamService) unsubscribe(sub *streamSubscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeLocked(sub)
+}
+
+func (s *streamService) removeLocked(sub *streamSubscriber) {
+	subs, ok := s.subscribers[sub.userID]
+	if !ok {
+		return
+	}
+
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(s.subscribers, sub.userID)
+	}
+	close(sub.events)
+}
+
+// Shutdown closes all subscriptions so that open streams end and the HTTP
+// server can shut down.
+func (s *streamService) Shutdown() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	for _, subs := range s.subscribers {
+		for sub := range subs {
+			s.removeLocked(sub)
+		}
+	}
+}
+
+// Run listens for events published by all replicas and sends heartbeats until ctx is done.
+func (s *streamService) Run(ctx context.Context) {
+	if err := s.listener.Listen(notificationChannel); err != nil {
+		s.logger.Error("failed to listen for notifications", "error", err)
+		return
+	}
+	defer s.listener.Close()
+
+	heartbeat := time.NewTicker(s.cfg.HeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n := <-s.listener.Notify:
+			// nil is sent after a reconnect, clients resume missed events by Last-Event-ID
+			if n == nil {
+				continue
+			}
+			s.dispatchNotification(ctx, n.Extra)
+		case <-heartbeat.C:
+			s.broadcast(StreamEvent{Kind: StreamEventPing})
+
+			// detects a broken listener connection while there is no traffic
+			if err := s.listener.Ping(); err != nil {
+				s.logger.Warn("notification listener ping failed", "error", err)
+			}
+		}
+	}
+}
+
+type notificationPayload struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+func (s *streamService) dispatchNotification(ctx context.Context, payload string) {
+	var p notificationPayload
+	if err := json.Unmarshal([]byte(payload), &p); err != nil {
+		s.logger.Error("failed to decode notification payload",
+			"payload", payload,
+			"error", err,
+		)
+		return
+	}
+
+	s.mu.Lock()
+	_, ok := s.subscribers[p.UserID]
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, streamLoadTimeout)
+	defer cancel()
+
+	notification, err := s.notificationRepository.GetByID(ctx, p.ID)
+	if err != nil {
+		if !errors.Is(err, domain.ErrNotFound) {
+			s.logger.Error("failed to load notification",
+				"notification_id", p.ID,
+				"error", err,
+			)
+		}
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for sub := range s.subscribers[p.UserID] {
+		s.sendLocked(sub, StreamEvent{Kind: StreamEventNotification, Notification: notification})
+	}
+}
+
+func (s *streamService) broadcast(event StreamEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, subs := range s.subscribers {
+		for sub := range subs {
+			s.sendLocked(sub, event)
+		}
+	}
+}
+
+// sendLocked never blocks, a subscriber that cannot keep up is dropped.
+func (s *streamService) sendLocked(sub *streamSubscriber, event StreamEvent) {
+	select {
+	case sub.events <- event:
+	default:
+		s.removeLocked(sub)
+	}
+}
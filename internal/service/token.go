@@ -0,0 +1,25 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+)
+
+// newSecretToken returns a random token to send to the user and the hash of
+// it that is stored instead of the token.
+func newSecretToken() (string, []byte, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, fmt.Errorf("generate secret token failed: %w", err)
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	return token, hashSecretToken(token), nil
+}
+
+func hashSecretToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Unsubscribe token scopes, a token for one list does not work for another.
+const (
+	unsubscribeNotifications = "unsubscribe"
+	unsubscribeNewsletter    = "newsletter-unsubscribe"
+)
+
+// unsubscribeToken is put in email links and lets the recipient turn emails
+// off without logging in. It never expires, so it must not grant anything else.
+func unsubscribeToken(secret, scope string, id uuid.UUID) string {
+	return id.String() + "." + base64.RawURLEncoding.EncodeToString(unsubscribeMAC(secret, scope, id))
+}
+
+func parseUnsubscribeToken(secret, scope, token string) (uuid.UUID, error) {
+	idStr, macStr, ok := strings.Cut(token, ".")
+	if !ok {
+		return uuid.Nil, ErrInvalidUnsubscribeToken
+	}
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return uuid.Nil, ErrInvalidUnsubscribeToken
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(macStr)
+	if err != nil || !hmac.Equal(mac, unsubscribeMAC(secret, scope, id)) {
+		return uuid.Nil, ErrInvalidUnsubscribeToken
+	}
+
+	return id, nil
+}
+
+func unsubscribeMAC(secret, scope string, id uuid.UUID) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(scope + ":" + id.String()))
+	return h.Sum(nil)
+}
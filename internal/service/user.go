@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/newnorthblog/backend/internal/domain"
 	"github.com/newnorthblog/backend/internal/pkg/tokenmanager"
@@ -15,20 +16,32 @@ import (
 )
 
 type userService struct {
-	userRepository repository.Users
-	logger         *slog.Logger
-	tokenManager   *tokenmanager.Manager
+	userRepository          repository.Users
+	passwordResetRepository repository.PasswordResets
+	txManager               repository.TxManager
+	audit                   auditor
+	events                  eventPublisher
+	logger                  *slog.Logger
+	tokenManager            *tokenmanager.Manager
 }
 
 func newUserService(
 	userRepository repository.Users,
+	passwordResetRepository repository.PasswordResets,
+	txManager repository.TxManager,
+	audit auditor,
+	events eventPublisher,
 	logger *slog.Logger,
 	tokenManager *tokenmanager.Manager,
 ) *userService {
 	return &userService{
-		userRepository: userRepository,
-		logger:         logger,
-		tokenManager:   tokenManager,
+		userRepository:          userRepository,
+		passwordResetRepository: passwordResetRepository,
+		txManager:               txManager,
+		audit:                   audit,
+		events:                  events,
+		logger:                  logger,
+		tokenManager:            tokenManager,
 	}
 }
 
@@ -49,18 +62,41 @@ func (s *userService) Register(ctx context.Context, input *RegisterInput) error
 		return fmt.Errorf("bcrypt.GenerateFromPassword failed: %w", err)
 	}
 
-	if err := s.userRepository.Create(ctx, &domain.User{
-		ID:       userID,
-		Username: input.Username,
-		Email:    input.Email,
-		Password: passHash,
-	}); err != nil {
-		if errors.Is(err, domain.ErrDuplicateEntry) {
-			return ErrUserAlreadyExists
+	// the event is written with the user, so it is relayed exactly when the
+	// user exists
+	if err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.userRepository.Create(ctx, &domain.User{
+			ID:       userID,
+			Username: input.Username,
+			Email:    input.Email,
+			Password: passHash,
+		}); err != nil {
+			if errors.Is(err, domain.ErrDuplicateEntry) {
+				return ErrUserAlreadyExists
+			}
+			return fmt.Errorf("create user failed: %w", err)
+		}
+
+		if err := s.events.Publish(ctx, domain.EventUserRegistered, map[string]any{
+			"id":       userID,
+			"username": input.Username,
+		}); err != nil {
+			return fmt.Errorf("publish user registered event failed: %w", err)
 		}
-		return fmt.Errorf("create user failed: %w", err)
+
+		return nil
+	}); err != nil {
+		return err
 	}
 
+	s.audit.Record(ctx, &AuditRecord{
+		Action:     domain.AuditActionUserRegister,
+		ActorID:    &userID,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   &userID,
+		Details:    map[string]any{"email": input.Email, "username": input.Username},
+	})
+
 	return nil
 }
 
@@ -73,22 +109,127 @@ func (s *userService) Login(ctx context.Context, email, password string) (*Token
 	user, err := s.userRepository.GetByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
+			s.recordLoginFailed(ctx, email, nil, ErrUserNotFound)
 			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("get user by email failed: %w", err)
 	}
 
 	if err = bcrypt.CompareHashAndPassword(user.Password, []byte(password)); err != nil {
+		s.recordLoginFailed(ctx, email, &user.ID, ErrUserInvalidCredentials)
 		return nil, ErrUserInvalidCredentials
 	}
 
+	if err := checkUserAccess(user); err != nil {
+		s.recordLoginFailed(ctx, email, &user.ID, err)
+		return nil, err
+	}
+
 	accessToken, _, err := s.tokenManager.NewJWT(&user.ID)
 	if err != nil {
 		return nil, fmt.Errorf("generate access token failed: %w", err)
 	}
 
+	s.audit.Record(ctx, &AuditRecord{
+		Action:     domain.AuditActionUserLogin,
+		ActorID:    &user.ID,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   &user.ID,
+	})
+
 	return &Tokens{
 		AccessToken:  accessToken,
 		RefreshToken: "",
 	}, nil
 }
+
+// recordLoginFailed records a rejected login, userID is nil when no user has
+// the email.
+func (s *userService) recordLoginFailed(ctx context.Context, email string, userID *uuid.UUID, reason error) {
+	record := &AuditRecord{
+		Action:  domain.AuditActionUserLoginFailed,
+		Details: map[string]any{"email": email, "reason": reason.Error()},
+	}
+	if userID != nil {
+		record.TargetType = domain.AuditTargetUser
+		record.TargetID = userID
+	}
+
+	s.audit.Record(ctx, record)
+}
+
+// GetActive returns the user if they may use the API, it is checked on every
+// authenticated request so that suspensions apply to already issued tokens.
+func (s *userService) GetActive(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	user, err := s.userRepository.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("get user by id failed: %w", err)
+	}
+
+	if err := checkUserAccess(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// ResetPassword sets a new password using a token sent by email, the token
+// can be used once.
+func (s *userService) ResetPassword(ctx context.Context, token, password string) error {
+	passHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("bcrypt.GenerateFromPassword failed: %w", err)
+	}
+
+	// the token stays valid if the password could not be changed
+	var reset *domain.PasswordReset
+	if err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		reset, err = s.passwordResetRepository.Use(ctx, hashSecretToken(token))
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				return ErrInvalidPasswordResetToken
+			}
+			return fmt.Errorf("use password reset failed: %w", err)
+		}
+
+		if err := s.userRepository.UpdatePassword(ctx, reset.UserID, passHash); err != nil {
+			if errors.Is(err, domain.ErrNoRowsAffected) {
+				return ErrInvalidPasswordResetToken
+			}
+			return fmt.Errorf("update password failed: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, &AuditRecord{
+		Action:     domain.AuditActionUserPasswordChange,
+		ActorID:    &reset.UserID,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   &reset.UserID,
+		Details:    map[string]any{"method": "reset_token"},
+	})
+
+	return nil
+}
+
+func checkUserAccess(user *domain.User) error {
+	if user.IsBanned() {
+		return ErrUserBanned
+	}
+
+	if user.IsSuspended(time.Now()) {
+		return ErrUserSuspended
+	}
+
+	if user.PasswordResetRequired {
+		return ErrUserPasswordResetRequired
+	}
+
+	return nil
+}
@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/newnorthblog/backend/internal/config"
+	"github.com/newnorthblog/backend/internal/domain"
+	"github.com/newnorthblog/backend/internal/pkg/mailer"
+	"github.com/newnorthblog/backend/internal/pkg/tokenmanager"
+	"github.com/newnorthblog/backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// userAdminService holds the admin actions on user accounts, every change is
+// recorded in the audit log with the admin who made it.
+type userAdminService struct {
+	userRepository          repository.Users
+	passwordResetRepository repository.PasswordResets
+	txManager               repository.TxManager
+	audit                   auditor
+	tokenManager            *tokenmanager.Manager
+	mailer                  mailer.Mailer
+	cfg                     *config.Config
+	logger                  *slog.Logger
+}
+
+func newUserAdminService(
+	userRepository repository.Users,
+	passwordResetRepository repository.PasswordResets,
+	txManager repository.TxManager,
+	audit auditor,
+	tokenManager *tokenmanager.Manager,
+	mailer mailer.Mailer,
+	cfg *config.Config,
+	logger *slog.Logger,
+) *userAdminService {
+	return &userAdminService{
+		userRepository:          userRepository,
+		passwordResetRepository: passwordResetRepository,
+		txManager:               txManager,
+		audit:                   audit,
+		tokenManager:            tokenManager,
+		mailer:                  mailer,
+		cfg:                     cfg,
+		logger:                  logger,
+	}
+}
+
+type ListUsersInput struct {
+	Search      string
+	Role        string
+	Status      string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Cursor      string
+	Limit       int
+}
+
+type UserList struct {
+	Users      []domain.User
+	NextCursor string
+}
+
+func (s *userAdminService) ListUsers(ctx context.Context, input *ListUsersInput) (*UserList, error) {
+	after, err := decodeCursor(input.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := pageLimit(input.Limit)
+	users, err := s.userRepository.List(ctx, &domain.UserFilter{
+		Search:      input.Search,
+		Role:        input.Role,
+		Status:      input.Status,
+		CreatedFrom: input.CreatedFrom,
+		CreatedTo:   input.CreatedTo,
+		Cursor:      after,
+		Limit:       limit + 1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list users failed: %w", err)
+	}
+
+	list := &UserList{Users: users}
+	if len(users) > limit {
+		list.Users = users[:limit]
+		last := list.Users[limit-1]
+		list.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+
+	return list, nil
+}
+
+// ChangeRole gives the user a new role, admins cannot change their own role
+// so that the last admin cannot lock everyone out by accident.
+func (s *userAdminService) ChangeRole(ctx context.Context, adminID, userID uuid.UUID, role string) (*domain.User, error) {
+	if adminID == userID {
+		return nil, ErrChangeOwnRole
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepository.UpdateRole(ctx, userID, role); err != nil {
+		return nil, fmt.Errorf("update user role failed: %w", err)
+	}
+
+	s.audit.Record(ctx, &AuditRecord{
+		Action:     domain.AuditActionUserRoleChange,
+		ActorID:    &adminID,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   &userID,
+		Details:    map[string]any{"old_role": user.Role, "role": role},
+	})
+
+	user.Role = role
+	return user, nil
+}
+
+// PromoteAdmin gives the admin role to the registered user with the email
+// from the config, it is how the first admin appears. A user who is an admin
+// already is left as is.
+func (s *userAdminService) PromoteAdmin(ctx context.Context, email string) error {
+	user, err := s.userRepository.GetByEmail(ctx, strings.TrimSpace(email))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("get user failed: %w", err)
+	}
+
+	if user.DeletedAt != nil {
+		return ErrUserNotFound
+	}
+
+	if user.Role == domain.UserRoleAdmin {
+		return nil
+	}
+
+	if err := s.userRepository.UpdateRole(ctx, user.ID, domain.UserRoleAdmin); err != nil {
+		return fmt.Errorf("update user role failed: %w", err)
+	}
+
+	s.audit.Record(ctx, &AuditRecord{
+		Action:     domain.AuditActionUserRoleChange,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   &user.ID,
+		Details:    map[string]any{"old_role": user.Role, "role": domain.UserRoleAdmin, "source": "config"},
+	})
+
+	s.logger.Info("user promoted to admin",
+		"user_id", user.ID,
+	)
+
+	return nil
+}
+
+// ForcePasswordReset locks the user out until they set a new password with
+// the token emailed to them. The lock and the token are saved together, the
+// email goes after that, and emailSent tells whether it went: the user stays
+// locked out either way, a new reset sends a new token.
+func (s *userAdminService) ForcePasswordReset(ctx context.Context, adminID, userID uuid.UUID) (emailSent bool, err error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	token, tokenHash, err := newSecretToken()
+	if err != nil {
+		return false, err
+	}
+
+	reset := &domain.PasswordReset{
+		TokenHash: tokenHash,
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(s.cfg.PasswordReset.TTL),
+	}
+
+	if err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.userRepository.RequirePasswordReset(ctx, userID); err != nil {
+			return fmt.Errorf("require password reset failed: %w", err)
+		}
+
+		if err := s.passwordResetRepository.Create(ctx, reset); err != nil {
+			return fmt.Errorf("create password reset failed: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return false, err
+	}
+
+	emailSent = true
+	if err := s.sendPasswordReset(ctx, user, token, reset.ExpiresAt); err != nil {
+		emailSent = false
+		s.logger.Error("failed to send forced password reset",
+			"user_id", userID,
+			"error", err,
+		)
+	}
+
+	s.audit.Record(ctx, &AuditRecord{
+		Action:     domain.AuditActionUserPasswordResetForce,
+		ActorID:    &adminID,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   &userID,
+		Details:    map[string]any{"email_sent": emailSent},
+	})
+
+	return emailSent, nil
+}
+
+func (s *userAdminService) sendPasswordReset(ctx context.Context, user *domain.User, token string, expiresAt time.Time) error {
+	msg, err := buildPasswordResetMessage(user, token, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		return fmt.Errorf("send password reset failed: %w", err)
+	}
+
+	return nil
+}
+
+func (s *userAdminService) VerifyEmail(ctx context.Context, adminID, userID uuid.UUID) (*domain.User, error) {
+	if err := s.userRepository.VerifyEmail(ctx, userID); err != nil {
+		if errors.Is(err, domain.ErrNoRowsAffected) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("verify user email failed: %w", err)
+	}
+
+	s.audit.Record(ctx, &AuditRecord{
+		Action:     domain.AuditActionUserEmailVerify,
+		ActorID:    &adminID,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   &userID,
+	})
+
+	return s.getUser(ctx, userID)
+}
+
+type ImpersonationToken struct {
+	AccessToken string
+	ExpiresAt   time.Time
+}
+
+// Impersonate issues a short-lived access token that lets the admin act as
+// the user, the token carries the admin id so that it can be told apart.
+func (s *userAdminService) Impersonate(ctx context.Context, adminID, userID uuid.UUID) (*ImpersonationToken, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Role == domain.UserRoleAdmin {
+		return nil, ErrImpersonateAdmin
+	}
+
+	ttl := s.cfg.JWT.ImpersonationTTL
+	accessToken, err := s.tokenManager.NewImpersonationJWT(&user.ID, &adminID, ttl)
+	if err != nil {
+		return nil, fmt.Errorf("generate impersonation token failed: %w", err)
+	}
+
+	s.audit.Record(ctx, &AuditRecord{
+		Action:     domain.AuditActionUserImpersonate,
+		ActorID:    &adminID,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   &userID,
+		Details:    map[string]any{"ttl": ttl.String()},
+	})
+
+	return &ImpersonationToken{
+		AccessToken: accessToken,
+		ExpiresAt:   time.Now().Add(ttl),
+	}, nil
+}
+
+func (s *userAdminService) getUser(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	user, err := s.userRepository.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("get user by id failed: %w", err)
+	}
+
+	return user, nil
+}
@@ -0,0 +1,537 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/newnorthblog/backend/internal/config"
+	"github.com/newnorthblog/backend/internal/domain"
+	"github.com/newnorthblog/backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	webhookEventHeader     = "X-Webhook-Event"
+	webhookDeliveryHeader  = "X-Webhook-Delivery"
+	webhookTimestampHeader = "X-Webhook-Timestamp"
+	webhookSignatureHeader = "X-Webhook-Signature"
+
+	webhookSecretPrefix = "whsec_"
+)
+
+// webhookPayload is the body every endpoint receives.
+type webhookPayload struct {
+	ID        uuid.UUID `json:"id"`
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// webhookService manages admin configured webhook endpoints and delivers
+// events to them from a queue in the database, retrying failed deliveries
+// with exponential backoff.
+type webhookService struct {
+	webhookRepository repository.Webhooks
+	audit             auditor
+	client            *http.Client
+	cfg               config.Webhook
+	logger            *slog.Logger
+}
+
+func newWebhookService(
+	webhookRepository repository.Webhooks,
+	audit auditor,
+	cfg config.Webhook,
+	logger *slog.Logger,
+) *webhookService {
+	return &webhookService{
+		webhookRepository: webhookRepository,
+		audit:             audit,
+		client:            newWebhookClient(cfg.Timeout),
+		cfg:               cfg,
+		logger:            logger,
+	}
+}
+
+// HandleEvent queues the relayed event for every active endpoint subscribed
+// to it. The payload carries the event id, so that receivers can drop an
+// event they got twice.
+func (s *webhookService) HandleEvent(ctx context.Context, event *domain.OutboxEvent) error {
+	payload, err := json.Marshal(webhookPayload{
+		ID:        event.ID,
+		Event:     event.Event,
+		CreatedAt: event.CreatedAt,
+		Data:      event.Payload,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal webhook payload failed: %w", err)
+	}
+
+	if _, err := s.webhookRepository.CreateDeliveries(ctx, event.Event, payload); err != nil {
+		return fmt.Errorf("create webhook deliveries failed: %w", err)
+	}
+
+	return nil
+}
+
+type WebhookEndpointInput struct {
+	URL    string
+	Events []string
+	Active bool
+}
+
+// CreateEndpoint adds an endpoint with a new signing secret, the secret is
+// only returned here.
+func (s *webhookService) CreateEndpoint(ctx context.Context, adminID uuid.UUID, input *WebhookEndpointInput) (*domain.WebhookEndpoint, error) {
+	if err := checkWebhookEvents(input.Events); err != nil {
+		return nil, err
+	}
+	if err := checkWebhookURL(input.URL); err != nil {
+		return nil, err
+	}
+
+	endpointID, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("generate webhook endpoint id failed: %w", err)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("generate webhook secret failed: %w", err)
+	}
+
+	endpoint := &domain.WebhookEndpoint{
+		ID:        endpointID,
+		URL:       input.URL,
+		Secret:    webhookSecretPrefix + base64.RawURLEncoding.EncodeToString(secret),
+		Events:    input.Events,
+		Active:    input.Active,
+		CreatedBy: &adminID,
+	}
+	if err := s.webhookRepository.CreateEndpoint(ctx, endpoint); err != nil {
+		return nil, fmt.Errorf("create webhook endpoint failed: %w", err)
+	}
+
+	s.audit.Record(ctx, &AuditRecord{
+		Action:     domain.AuditActionWebhookCreate,
+		ActorID:    &adminID,
+		TargetType: domain.AuditTargetWebhook,
+		TargetID:   &endpointID,
+		Details:    map[string]any{"url": endpoint.URL, "events": endpoint.Events, "active": endpoint.Active},
+	})
+
+	return endpoint, nil
+}
+
+func (s *webhookService) ListEndpoints(ctx context.Context) ([]domain.WebhookEndpoint, error) {
+	endpoints, err := s.webhookRepository.ListEndpoints(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list webhook endpoints failed: %w", err)
+	}
+
+	return endpoints, nil
+}
+
+func (s *webhookService) UpdateEndpoint(ctx context.Context, adminID, endpointID uuid.UUID, input *WebhookEndpointInput) (*domain.WebhookEndpoint, error) {
+	if err := checkWebhookEvents(input.Events); err != nil {
+		return nil, err
+	}
+	if err := checkWebhookURL(input.URL); err != nil {
+		return nil, err
+	}
+
+	endpoint := &domain.WebhookEndpoint{
+		ID:     endpointID,
+		URL:    input.URL,
+		Events: input.Events,
+		Active: input.Active,
+	}
+	if err := s.webhookRepository.UpdateEndpoint(ctx, endpoint); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("update webhook endpoint failed: %w", err)
+	}
+
+	s.audit.Record(ctx, &AuditRecord{
+		Action:     domain.AuditActionWebhookUpdate,
+		ActorID:    &adminID,
+		TargetType: domain.AuditTargetWebhook,
+		TargetID:   &endpointID,
+		Details:    map[string]any{"url": endpoint.URL, "events": endpoint.Events, "active": endpoint.Active},
+	})
+
+	return endpoint, nil
+}
+
+// DeleteEndpoint removes the endpoint together with its deliveries.
+func (s *webhookService) DeleteEndpoint(ctx context.Context, adminID, endpointID uuid.UUID) error {
+	if err := s.webhookRepository.DeleteEndpoint(ctx, endpointID); err != nil {
+		if errors.Is(err, domain.ErrNoRowsAffected) {
+			return ErrWebhookNotFound
+		}
+		return fmt.Errorf("delete webhook endpoint failed: %w", err)
+	}
+
+	s.audit.Record(ctx, &AuditRecord{
+		Action:     domain.AuditActionWebhookDelete,
+		ActorID:    &adminID,
+		TargetType: domain.AuditTargetWebhook,
+		TargetID:   &endpointID,
+	})
+
+	return nil
+}
+
+type WebhookDeliveryList struct {
+	Deliveries []domain.WebhookDelivery
+	NextCursor string
+}
+
+func (s *webhookService) ListDeliveries(ctx context.Context, endpointID uuid.UUID, cursor string, limit int) (*WebhookDeliveryList, error) {
+	after, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.getEndpoint(ctx, endpointID); err != nil {
+		return nil, err
+	}
+
+	limit = pageLimit(limit)
+	deliveries, err := s.webhookRepository.ListDeliveries(ctx, endpointID, after, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("list webhook deliveries failed: %w", err)
+	}
+
+	list := &WebhookDeliveryList{Deliveries: deliveries}
+	if len(deliveries) > limit {
+		list.Deliveries = deliveries[:limit]
+		last := list.Deliveries[limit-1]
+		list.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+
+	return list, nil
+}
+
+// GetDelivery returns the delivery with the log of its attempts.
+func (s *webhookService) GetDelivery(ctx context.Context, deliveryID uuid.UUID) (*domain.WebhookDelivery, []domain.WebhookDeliveryAttempt, error) {
+	delivery, err := s.getDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attempts, err := s.webhookRepository.ListAttempts(ctx, deliveryID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list webhook delivery attempts failed: %w", err)
+	}
+
+	return delivery, attempts, nil
+}
+
+// Redeliver sends the delivery again, whatever its status, with a fresh
+// attempt budget.
+func (s *webhookService) Redeliver(ctx context.Context, adminID, deliveryID uuid.UUID) (*domain.WebhookDelivery, error) {
+	delivery, err := s.getDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.webhookRepository.Redeliver(ctx, delivery); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrWebhookDeliveryNotFound
+		}
+		return nil, fmt.Errorf("redeliver webhook delivery failed: %w", err)
+	}
+
+	s.audit.Record(ctx, &AuditRecord{
+		Action:     domain.AuditActionWebhookRedeliver,
+		ActorID:    &adminID,
+		TargetType: domain.AuditTargetWebhookDelivery,
+		TargetID:   &deliveryID,
+	})
+
+	return delivery, nil
+}
+
+// Run delivers due webhooks every interval until ctx is done.
+func (s *webhookService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.deliver(ctx); err != nil {
+				s.logger.Error("failed to deliver webhooks", "error", err)
+			}
+		}
+	}
+}
+
+func (s *webhookService) deliver(ctx context.Context) error {
+	// the lease outlasts a request and the batch is sent at once, so that it
+	// is done before another replica may claim the same deliveries
+	deliveries, err := s.webhookRepository.ClaimDue(ctx, s.cfg.BatchSize, 2*s.cfg.Timeout)
+	if err != nil {
+		return fmt.Errorf("claim webhook deliveries failed: %w", err)
+	}
+
+	endpoints := make(map[uuid.UUID]*domain.WebhookEndpoint)
+	for _, delivery := range deliveries {
+		if _, ok := endpoints[delivery.EndpointID]; ok {
+			continue
+		}
+
+		endpoint, err := s.webhookRepository.GetEndpoint(ctx, delivery.EndpointID)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return fmt.Errorf("get webhook endpoint failed: %w", err)
+		}
+		endpoints[delivery.EndpointID] = endpoint
+	}
+
+	var wg sync.WaitGroup
+	for i := range deliveries {
+		delivery := &deliveries[i]
+
+		// a deleted endpoint takes its deliveries with it
+		endpoint := endpoints[delivery.EndpointID]
+		if endpoint == nil {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.attempt(ctx, endpoint, delivery); err != nil {
+				s.logger.Error("failed to save webhook delivery attempt",
+					"delivery_id", delivery.ID,
+					"error", err,
+				)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// attempt sends the delivery once and schedules the next attempt if it failed.
+func (s *webhookService) attempt(ctx context.Context, endpoint *domain.WebhookEndpoint, delivery *domain.WebhookDelivery) error {
+	attemptID, err := uuid.NewV7()
+	if err != nil {
+		return fmt.Errorf("generate webhook attempt id failed: %w", err)
+	}
+
+	start := time.Now()
+	var (
+		status  *int
+		sendErr error
+	)
+	if endpoint.Active {
+		status, sendErr = s.send(ctx, endpoint, delivery)
+	} else {
+		sendErr = errors.New("endpoint is inactive")
+	}
+	if ctx.Err() != nil {
+		// shutting down, the lease runs out and the delivery is picked up again
+		return nil
+	}
+
+	delivery.Attempts++
+	delivery.ResponseStatus = status
+	delivery.LastError = nil
+
+	attempt := &domain.WebhookDeliveryAttempt{
+		ID:             attemptID,
+		DeliveryID:     delivery.ID,
+		ResponseStatus: status,
+		DurationMS:     int(time.Since(start).Milliseconds()),
+	}
+
+	var retryIn time.Duration
+	switch {
+	case sendErr == nil:
+		delivery.Status = domain.WebhookDeliveryStatusSucceeded
+	case delivery.Attempts >= s.cfg.MaxAttempts || !endpoint.Active:
+		delivery.Status = domain.WebhookDeliveryStatusFailed
+	default:
+		retryIn = backoff(s.cfg.BackoffBase, s.cfg.BackoffMax, delivery.Attempts)
+	}
+
+	if sendErr != nil {
+		errText := sendErr.Error()
+		delivery.LastError = &errText
+		attempt.Error = &errText
+	}
+
+	return s.webhookRepository.SaveAttempt(ctx, delivery, attempt, retryIn)
+}
+
+// send posts the payload signed with the endpoint secret, any 2xx response
+// is a success.
+func (s *webhookService) send(ctx context.Context, endpoint *domain.WebhookEndpoint, delivery *domain.WebhookDelivery) (*int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return nil, fmt.Errorf("create request failed: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhookEventHeader, delivery.Event)
+	req.Header.Set(webhookDeliveryHeader, delivery.ID.String())
+	req.Header.Set(webhookTimestampHeader, timestamp)
+	req.Header.Set(webhookSignatureHeader, "sha256="+signWebhook(endpoint.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	// read a little of the body so that the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	status := resp.StatusCode
+	if status < 200 || status > 299 {
+		return &status, fmt.Errorf("unexpected status %d", status)
+	}
+
+	return &status, nil
+}
+
+func (s *webhookService) getEndpoint(ctx context.Context, id uuid.UUID) (*domain.WebhookEndpoint, error) {
+	endpoint, err := s.webhookRepository.GetEndpoint(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("get webhook endpoint failed: %w", err)
+	}
+
+	return endpoint, nil
+}
+
+func (s *webhookService) getDelivery(ctx context.Context, id uuid.UUID) (*domain.WebhookDelivery, error) {
+	delivery, err := s.webhookRepository.GetDelivery(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrWebhookDeliveryNotFound
+		}
+		return nil, fmt.Errorf("get webhook delivery failed: %w", err)
+	}
+
+	return delivery, nil
+}
+
+// signWebhook signs the timestamp together with the body, so that a
+// captured request cannot be replayed later with a new timestamp.
+func signWebhook(secret, timestamp string, payload []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(timestamp + "."))
+	h.Write(payload)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func checkWebhookEvents(events []string) error {
+	for _, event := range events {
+		if !slices.Contains(domain.WebhookEvents, event) {
+			return ErrUnknownWebhookEvent
+		}
+	}
+
+	return nil
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, some clouds keep their
+// metadata services there.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// checkWebhookURL accepts only http and https URLs. An address in the URL is
+// checked here, host names are checked once resolved, when dialed.
+func checkWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrInvalidWebhookURL
+	}
+	if strings.EqualFold(u.Hostname(), "localhost") {
+		return ErrInvalidWebhookURL
+	}
+	if addr, err := netip.ParseAddr(u.Hostname()); err == nil && !isPublicAddr(addr) {
+		return ErrInvalidWebhookURL
+	}
+
+	return nil
+}
+
+// newWebhookClient returns a client that reaches public addresses only, so
+// that an endpoint cannot make the server call itself, the cloud metadata
+// service or the internal network. Redirects are not followed, a 3xx
+// response is a failed attempt.
+func newWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: webhookDialControl,
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// a proxy would dial the endpoint itself, past the check
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// webhookDialControl runs with the resolved address, so a host name that
+// resolves to a private address is rejected as well.
+func webhookDialControl(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !isPublicAddr(addr) {
+		return fmt.Errorf("address %s is not public", addr)
+	}
+
+	return nil
+}
+
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() &&
+		!addr.IsPrivate() &&
+		!sharedAddressSpace.Contains(addr)
+}
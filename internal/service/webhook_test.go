@@ -0,0 +1,61 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCheckWebhookURL(t *testing.T) {
+	tests := []struct {
+		url string
+		ok  bool
+	}{
+		{"https://hooks.example.com/in", true},
+		{"http://93.184.216.34:8080/in", true},
+		{"https://[2606:2800:220:1::1]/in", true},
+		{"ftp://hooks.example.com/in", false},
+		{"file:///etc/passwd", false},
+		{"https:///in", false},
+		{"http://localhost:8080/in", false},
+		{"http://127.0.0.1/in", false},
+		{"http://10.0.0.5/in", false},
+		{"http://192.168.1.1/in", false},
+		{"http://169.254.169.254/latest/meta-data/", false},
+		{"http://100.100.100.200/", false},
+		{"http://0.0.0.0/", false},
+		{"http://[::1]/in", false},
+		{"http://[fe80::1]/in", false},
+		{"http://[fd00::1]/in", false},
+		{"http://[::ffff:127.0.0.1]/in", false},
+	}
+
+	for _, tt := range tests {
+		err := checkWebhookURL(tt.url)
+		if tt.ok && err != nil {
+			t.Errorf("checkWebhookURL(%q) = %v, want nil", tt.url, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrInvalidWebhookURL) {
+			t.Errorf("checkWebhookURL(%q) = %v, want ErrInvalidWebhookURL", tt.url, err)
+		}
+	}
+}
+
+func TestWebhookClientRejectsLoopback(t *testing.T) {
+	called := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		called = true
+	}))
+	defer srv.Close()
+
+	resp, err := newWebhookClient(time.Second).Get(srv.URL)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("the client reached a loopback address")
+	}
+	if called {
+		t.Error("the request got to the server")
+	}
+}
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE media
+    ADD COLUMN status VARCHAR(32) NOT NULL DEFAULT 'ready',
+    ADD COLUMN width INT NOT NULL DEFAULT 0,
+    ADD COLUMN height INT NOT NULL DEFAULT 0,
+    ADD COLUMN original_key VARCHAR(512);
+
+CREATE INDEX media_processing_idx ON media (created_at) WHERE status = 'processing';
+
+CREATE TABLE media_variant (
+    media_id UUID NOT NULL REFERENCES media (id) ON DELETE CASCADE,
+    width INT NOT NULL,
+    height INT NOT NULL,
+    storage_key VARCHAR(512) NOT NULL UNIQUE,
+    mime_type VARCHAR(127) NOT NULL,
+    PRIMARY KEY (media_id, width)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE media_variant;
+
+DROP INDEX media_processing_idx;
+
+ALTER TABLE media
+    DROP COLUMN status,
+    DROP COLUMN width,
+    DROP COLUMN height,
+    DROP COLUMN original_key;
+-- +goose StatementEnd
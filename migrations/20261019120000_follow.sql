@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+-- usernames were never unique, renaming someone here would silently change
+-- their public profile link, so the duplicates have to be resolved by hand
+DO $$
+DECLARE
+    duplicates TEXT;
+BEGIN
+    SELECT string_agg(format('%L (%s accounts)', username, total), ', ' ORDER BY username)
+    INTO duplicates
+    FROM (
+        SELECT username, COUNT(*) AS total
+        FROM "user"
+        GROUP BY username
+        HAVING COUNT(*) > 1
+    ) d;
+
+    IF duplicates IS NOT NULL THEN
+        RAISE EXCEPTION 'cannot make usernames unique, duplicate usernames: %', duplicates
+            USING HINT = 'rename all but one account of each username, deleted accounts included, then rerun the migration';
+    END IF;
+END;
+$$;
+
+CREATE UNIQUE INDEX user_username_key ON "user" (username);
+
+CREATE TABLE follow (
+    follower_id UUID NOT NULL REFERENCES "user" (id) ON DELETE CASCADE,
+    followee_id UUID NOT NULL REFERENCES "user" (id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (follower_id, followee_id),
+    CHECK (follower_id <> followee_id)
+);
+
+CREATE INDEX follow_follower_id_created_at_idx ON follow (follower_id, created_at DESC, followee_id DESC);
+CREATE INDEX follow_followee_id_created_at_idx ON follow (followee_id, created_at DESC, follower_id DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE follow;
+
+DROP INDEX user_username_key;
+-- +goose StatementEnd
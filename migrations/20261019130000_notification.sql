@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE notification (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES "user" (id) ON DELETE CASCADE,
+    actor_id UUID REFERENCES "user" (id) ON DELETE CASCADE,
+    kind VARCHAR(32) NOT NULL,
+    target_id UUID,
+    read_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX notification_user_id_created_at_idx ON notification (user_id, created_at DESC, id DESC);
+CREATE INDEX notification_user_id_unread_idx ON notification (user_id) WHERE read_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE notification;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE FUNCTION notification_notify() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('notification', json_build_object('id', NEW.id, 'user_id', NEW.user_id)::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER notification_notify
+    AFTER INSERT ON notification
+    FOR EACH ROW EXECUTE FUNCTION notification_notify();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER notification_notify ON notification;
+
+DROP FUNCTION notification_notify();
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE notification_setting (
+    user_id UUID NOT NULL REFERENCES "user" (id) ON DELETE CASCADE,
+    kind VARCHAR(32) NOT NULL,
+    frequency VARCHAR(16) NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, kind)
+);
+
+ALTER TABLE notification ADD COLUMN emailed_at TIMESTAMP;
+ALTER TABLE notification ADD COLUMN email_claimed_until TIMESTAMP;
+
+-- existing notifications are not sent by email
+UPDATE notification SET emailed_at = NOW();
+
+CREATE INDEX notification_email_pending_idx ON notification (user_id, created_at) WHERE emailed_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX notification_email_pending_idx;
+
+ALTER TABLE notification DROP COLUMN email_claimed_until;
+ALTER TABLE notification DROP COLUMN emailed_at;
+
+DROP TABLE notification_setting;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE "user"
+    ADD COLUMN role VARCHAR(16) NOT NULL DEFAULT 'user',
+    ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'active',
+    ADD COLUMN suspended_until TIMESTAMP;
+
+CREATE TABLE report (
+    id UUID PRIMARY KEY,
+    reporter_id UUID NOT NULL REFERENCES "user" (id) ON DELETE CASCADE,
+    target_type VARCHAR(16) NOT NULL,
+    target_id UUID NOT NULL,
+    reason VARCHAR(1000) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'open',
+    resolved_by UUID REFERENCES "user" (id) ON DELETE SET NULL,
+    resolved_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX report_open_key ON report (reporter_id, target_type, target_id) WHERE status = 'open';
+CREATE INDEX report_status_created_at_idx ON report (status, created_at DESC, id DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE report;
+
+ALTER TABLE "user"
+    DROP COLUMN role,
+    DROP COLUMN status,
+    DROP COLUMN suspended_until;
+-- +goose StatementEnd
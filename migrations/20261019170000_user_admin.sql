@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE "user"
+    ADD COLUMN email_verified_at TIMESTAMP,
+    ADD COLUMN password_reset_required BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX user_created_at_idx ON "user" (created_at DESC, id DESC);
+
+CREATE TABLE password_reset (
+    token_hash BYTEA PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES "user" (id) ON DELETE CASCADE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX password_reset_user_id_idx ON password_reset (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE password_reset;
+
+DROP INDEX user_created_at_idx;
+
+ALTER TABLE "user"
+    DROP COLUMN email_verified_at,
+    DROP COLUMN password_reset_required;
+-- +goose StatementEnd
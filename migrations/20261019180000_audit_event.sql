@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE audit_event (
+    id UUID PRIMARY KEY,
+    action VARCHAR(64) NOT NULL,
+    actor_id UUID,
+    -- the admin who acted on behalf of actor_id with an impersonation token
+    impersonator_id UUID,
+    ip VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent VARCHAR(512) NOT NULL DEFAULT '',
+    target_type VARCHAR(16) NOT NULL DEFAULT '',
+    target_id UUID,
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX audit_event_created_at_idx ON audit_event (created_at DESC, id DESC);
+CREATE INDEX audit_event_actor_id_idx ON audit_event (actor_id, created_at DESC);
+CREATE INDEX audit_event_target_id_idx ON audit_event (target_id, created_at DESC);
+CREATE INDEX audit_event_impersonator_id_idx ON audit_event (impersonator_id, created_at DESC)
+    WHERE impersonator_id IS NOT NULL;
+
+-- the log is append-only, rows are never changed or removed
+CREATE FUNCTION audit_event_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_event is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_event_immutable
+    BEFORE UPDATE OR DELETE ON audit_event
+    FOR EACH ROW EXECUTE FUNCTION audit_event_immutable();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE audit_event;
+
+DROP FUNCTION audit_event_immutable();
+-- +goose StatementEnd
@@ -0,0 +1,51 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE subscriber (
+    id UUID PRIMARY KEY,
+    email VARCHAR(255) NOT NULL UNIQUE,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    confirm_token_hash BYTEA UNIQUE,
+    confirm_expires_at TIMESTAMP,
+    confirmed_at TIMESTAMP,
+    unsubscribed_at TIMESTAMP,
+    disabled_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX subscriber_active_idx ON subscriber (id) WHERE status = 'active';
+
+CREATE TABLE newsletter_issue (
+    id UUID PRIMARY KEY,
+    subject VARCHAR(255) NOT NULL,
+    text_body TEXT NOT NULL,
+    html_body TEXT NOT NULL DEFAULT '',
+    status VARCHAR(16) NOT NULL DEFAULT 'draft',
+    created_by UUID REFERENCES "user" (id) ON DELETE SET NULL,
+    send_started_at TIMESTAMP,
+    sent_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX newsletter_issue_created_at_idx ON newsletter_issue (created_at DESC, id DESC);
+
+CREATE TABLE newsletter_delivery (
+    issue_id UUID NOT NULL REFERENCES newsletter_issue (id) ON DELETE CASCADE,
+    subscriber_id UUID NOT NULL REFERENCES subscriber (id) ON DELETE CASCADE,
+    status VARCHAR(16) NOT NULL DEFAULT 'claimed',
+    -- a claim left by a replica that stopped mid-batch is taken over after it
+    claimed_until TIMESTAMP,
+    error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (issue_id, subscriber_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE newsletter_delivery;
+
+DROP TABLE newsletter_issue;
+
+DROP TABLE subscriber;
+-- +goose StatementEnd
@@ -1,85 +1,23 @@
 package limiter
 
 import (
+	"context"
 	"log/slog"
 	"net"
 	"net/http"
-	"sync"
-	"time"
 
 	"github.com/gin-gonic/gin"
-	"golang.org/x/time/rate"
 )
 
-// visitor holds limiter and lastSeen for specific user.
-type visitor struct {
-	limiter  *rate.Limiter
-	lastSeen time.Time
-}
-
-// rateLimiter used to rate limit an incoming requests.
-type rateLimiter struct {
-	sync.RWMutex
-
-	visitors map[string]*visitor
-	limit    rate.Limit
-	burst    int
-	ttl      time.Duration
-}
-
-// newRateLimiter creates an instance of the rateLimiter.
-func newRateLimiter(rps, burst int, ttl time.Duration) *rateLimiter {
-	return &rateLimiter{
-		visitors: make(map[string]*visitor),
-		limit:    rate.Limit(rps),
-		burst:    burst,
-		ttl:      ttl,
-	}
-}
-
-// getVisitor returns limiter for the specific visitor by its IP,
-// looking up within the visitors map.
-func (l *rateLimiter) getVisitor(ip string) *rate.Limiter {
-	l.RLock()
-	v, exists := l.visitors[ip]
-	l.RUnlock()
-
-	if !exists {
-		limiter := rate.NewLimiter(l.limit, l.burst)
-		l.Lock()
-		l.visitors[ip] = &visitor{limiter, time.Now()}
-		l.Unlock()
-
-		return limiter
-	}
-
-	v.lastSeen = time.Now()
-
-	return v.limiter
-}
-
-// cleanupVisitors removes old entries from the visitors map.
-func (l *rateLimiter) cleanupVisitors() {
-	for {
-		time.Sleep(time.Minute)
-
-		l.Lock()
-		for ip, v := range l.visitors {
-			if time.Since(v.lastSeen) > l.ttl {
-				delete(l.visitors, ip)
-			}
-		}
-		l.Unlock()
-	}
+// Store keeps the request budget of every visitor.
+type Store interface {
+	// Allow spends one request of the visitor's budget and reports whether
+	// there was any left.
+	Allow(ctx context.Context, key string) (bool, error)
 }
 
 // Limit creates a new rate limiter middleware handler.
-func Limit(rps int, burst int, ttl time.Duration, logger *slog.Logger) gin.HandlerFunc {
-	l := newRateLimiter(rps, burst, ttl)
-
-	// run a background worker to clean up old entries
-	go l.cleanupVisitors()
-
+func Limit(store Store, logger *slog.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ip, _, err := net.SplitHostPort(c.Request.RemoteAddr)
 		if err != nil {
@@ -89,7 +27,14 @@ func Limit(rps int, burst int, ttl time.Duration, logger *slog.Logger) gin.Handl
 			return
 		}
 
-		if !l.getVisitor(ip).Allow() {
+		allowed, err := store.Allow(c.Request.Context(), ip)
+		if err != nil {
+			// an unavailable store must not take the whole API down with it
+			logger.Error("rate limiter store failed", "error", err)
+			allowed = true
+		}
+
+		if !allowed {
 			c.AbortWithStatus(http.StatusTooManyRequests)
 
 			return
@@ -0,0 +1,82 @@
+package limiter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// visitor holds limiter and lastSeen for specific user.
+type visitor struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// MemoryStore keeps visitors in process, every replica has its own budgets
+// and they are lost on restart.
+type MemoryStore struct {
+	sync.RWMutex
+
+	visitors map[string]*visitor
+	limit    rate.Limit
+	burst    int
+	ttl      time.Duration
+}
+
+// NewMemoryStore creates an instance of the MemoryStore and starts removing
+// visitors unseen for ttl.
+func NewMemoryStore(rps, burst int, ttl time.Duration) *MemoryStore {
+	s := &MemoryStore{
+		visitors: make(map[string]*visitor),
+		limit:    rate.Limit(rps),
+		burst:    burst,
+		ttl:      ttl,
+	}
+
+	// run a background worker to clean up old entries
+	go s.cleanupVisitors()
+
+	return s
+}
+
+func (s *MemoryStore) Allow(_ context.Context, key string) (bool, error) {
+	return s.getVisitor(key).Allow(), nil
+}
+
+// getVisitor returns limiter for the specific visitor by its IP,
+// looking up within the visitors map.
+func (s *MemoryStore) getVisitor(ip string) *rate.Limiter {
+	s.RLock()
+	v, exists := s.visitors[ip]
+	s.RUnlock()
+
+	if !exists {
+		limiter := rate.NewLimiter(s.limit, s.burst)
+		s.Lock()
+		s.visitors[ip] = &visitor{limiter, time.Now()}
+		s.Unlock()
+
+		return limiter
+	}
+
+	v.lastSeen = time.Now()
+
+	return v.limiter
+}
+
+// cleanupVisitors removes old entries from the visitors map.
+func (s *MemoryStore) cleanupVisitors() {
+	for {
+		time.Sleep(time.Minute)
+
+		s.Lock()
+		for ip, v := range s.visitors {
+			if time.Since(v.lastSeen) > s.ttl {
+				delete(s.visitors, ip)
+			}
+		}
+		s.Unlock()
+	}
+}
@@ -0,0 +1,74 @@
+package limiter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const redisKeyPrefix = "limiter:"
+
+// gcraScript implements the generic cell rate algorithm. The key holds the
+// theoretical arrival time (TAT) of the next request in microseconds, it
+// moves one emission interval forward with every allowed request and a
+// request is allowed while the TAT stays within burst intervals from now.
+// The time comes from Redis, so the replicas need not agree on their clocks.
+//
+// KEYS[1] - visitor key
+// ARGV[1] - emission interval in microseconds
+// ARGV[2] - burst
+var gcraScript = redis.NewScript(`
+local now = redis.call('TIME')
+now = tonumber(now[1]) * 1000000 + tonumber(now[2])
+local interval = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+
+local tat = tonumber(redis.call('GET', KEYS[1]))
+if tat == nil or tat < now then
+	tat = now
+end
+
+local next_tat = tat + interval
+if next_tat - now > interval * burst then
+	return 0
+end
+
+redis.call('SET', KEYS[1], string.format('%d', next_tat), 'PX', math.ceil((next_tat - now) / 1000))
+return 1
+`)
+
+// RedisStore keeps visitors in Redis, so that all replicas share the same
+// budgets. It behaves like MemoryStore: rps requests per second with bursts
+// of up to burst requests.
+type RedisStore struct {
+	client   redis.Scripter
+	interval time.Duration
+	burst    int
+}
+
+// NewRedisStore creates an instance of the RedisStore, client is usually a
+// *redis.Client.
+func NewRedisStore(client redis.Scripter, rps, burst int) (*RedisStore, error) {
+	if rps <= 0 {
+		return nil, fmt.Errorf("rps must be positive, got %d", rps)
+	}
+
+	return &RedisStore{
+		client:   client,
+		interval: time.Second / time.Duration(rps),
+		burst:    burst,
+	}, nil
+}
+
+func (s *RedisStore) Allow(ctx context.Context, key string) (bool, error) {
+	allowed, err := gcraScript.Run(ctx, s.client, []string{redisKeyPrefix + key},
+		s.interval.Microseconds(), s.burst,
+	).Int()
+	if err != nil {
+		return false, fmt.Errorf("run gcra script failed: %w", err)
+	}
+
+	return allowed == 1, nil
+}
@@ -0,0 +1,113 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRedisStore(t *testing.T, rps, burst int) (*RedisStore, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	// the script takes the time from Redis, pin it so that nothing refills
+	// unless the test moves the clock
+	mr.SetTime(time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC))
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	store, err := NewRedisStore(client, rps, burst)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return store, mr
+}
+
+// allowN calls Allow n times and returns how many calls were allowed.
+func allowN(t *testing.T, store *RedisStore, key string, n int) int {
+	t.Helper()
+
+	allowed := 0
+	for range n {
+		ok, err := store.Allow(context.Background(), key)
+		if err != nil {
+			t.Fatalf("Allow: %v", err)
+		}
+		if ok {
+			allowed++
+		}
+	}
+
+	return allowed
+}
+
+func TestRedisStoreBurst(t *testing.T) {
+	store, _ := newTestRedisStore(t, 10, 5)
+
+	if got := allowN(t, store, "1.2.3.4", 8); got != 5 {
+		t.Errorf("allowed %d of 8 requests, want the burst of 5", got)
+	}
+
+	// visitors have separate budgets
+	if got := allowN(t, store, "5.6.7.8", 1); got != 1 {
+		t.Errorf("another visitor was limited")
+	}
+}
+
+func TestRedisStoreRefill(t *testing.T) {
+	store, mr := newTestRedisStore(t, 10, 5)
+	start := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	if got := allowN(t, store, "visitor", 5); got != 5 {
+		t.Fatalf("allowed %d of the burst, want 5", got)
+	}
+
+	// 10 rps refills one request every 100ms
+	mr.SetTime(start.Add(50 * time.Millisecond))
+	if got := allowN(t, store, "visitor", 1); got != 0 {
+		t.Errorf("allowed a request before the interval passed")
+	}
+
+	mr.SetTime(start.Add(100 * time.Millisecond))
+	if got := allowN(t, store, "visitor", 3); got != 1 {
+		t.Errorf("allowed %d requests after one interval, want 1", got)
+	}
+
+	mr.SetTime(start.Add(350 * time.Millisecond))
+	if got := allowN(t, store, "visitor", 5); got != 2 {
+		t.Errorf("allowed %d requests after two more intervals, want 2", got)
+	}
+
+	// an idle visitor gets the whole burst back, never more
+	mr.SetTime(start.Add(time.Hour))
+	if got := allowN(t, store, "visitor", 8); got != 5 {
+		t.Errorf("allowed %d requests after a long pause, want the burst of 5", got)
+	}
+}
+
+func TestRedisStoreExpiresIdleVisitors(t *testing.T) {
+	store, mr := newTestRedisStore(t, 10, 5)
+
+	allowN(t, store, "visitor", 5)
+
+	ttl := mr.TTL(redisKeyPrefix + "visitor")
+	if ttl <= 0 || ttl > 500*time.Millisecond {
+		t.Fatalf("TTL = %v, want up to the time the burst takes to refill", ttl)
+	}
+
+	mr.FastForward(ttl)
+	if mr.Exists(redisKeyPrefix + "visitor") {
+		t.Errorf("visitor key was not removed after its TTL")
+	}
+}
+
+func TestNewRedisStoreRejectsZeroRate(t *testing.T) {
+	if _, err := NewRedisStore(nil, 0, 5); err == nil {
+		t.Error("NewRedisStore accepted 0 rps")
+	}
+}